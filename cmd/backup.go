@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/yugo-ibuki/dot-claude-sync/config"
+	"github.com/yugo-ibuki/dot-claude-sync/syncer"
 	"github.com/yugo-ibuki/dot-claude-sync/utils"
 )
 
@@ -22,6 +24,9 @@ Example: .claude/bk/20250117-143025/`,
 	RunE: runBackup,
 }
 
+// backupTimestampFormat names the timestamped backup directories in .claude/bk
+const backupTimestampFormat = "20060102-150405"
+
 func init() {
 	rootCmd.AddCommand(backupCmd)
 }
@@ -43,12 +48,12 @@ func runBackup(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Loading configuration...\n")
 	}
 
-	cfg, err := config.Load(cfgFile)
+	cfg, err := config.LoadWithLocal(cfgFile)
 	if err != nil {
 		return err
 	}
 
-	group, err := cfg.GetGroup(groupName)
+	group, err := cfg.GetEffectiveGroup(groupName)
 	if err != nil {
 		availableGroups := cfg.ListGroups()
 		return fmt.Errorf("%w\nAvailable groups: %v", err, availableGroups)
@@ -67,13 +72,17 @@ func runBackup(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Creating backups for group '%s'...\n", groupName)
 
 	var results []BackupResult
-	timestamp := time.Now().Format("20060102-150405")
+	timestamp := time.Now().Format(backupTimestampFormat)
 
 	for _, project := range projects {
 		result := backupProject(project, timestamp, dryRun, verbose)
 		results = append(results, result)
 	}
 
+	if group.Backup != nil && group.Backup.Keep > 0 && !dryRun {
+		pruneProjectBackups(projects, group.Backup.Keep, verbose)
+	}
+
 	// Print results
 	fmt.Println()
 	successCount := 0
@@ -119,12 +128,12 @@ func backupProject(project config.ProjectPath, timestamp string, dryRun, verbose
 		Project: project.Alias,
 	}
 
-	claudeDir := expandPath(project.Path)
+	claudeDir := utils.ExpandHome(project.Path)
 
-	// Check if .claude directory exists
+	// Check if the synced directory exists
 	if !utils.FileExists(claudeDir) {
 		result.Skipped = true
-		result.SkipReason = ".claude directory does not exist"
+		result.SkipReason = syncer.SyncedDirName(project) + " directory does not exist"
 		return result
 	}
 
@@ -187,3 +196,58 @@ func countFiles(dir string) (int, error) {
 	})
 	return count, err
 }
+
+// pruneProjectBackups removes old backups beyond keep in every project, reporting failures as warnings
+func pruneProjectBackups(projects []config.ProjectPath, keep int, verbose bool) {
+	for _, project := range projects {
+		removed, err := pruneBackups(utils.ExpandHome(project.Path), keep)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to prune backups in %s: %v\n", project.Alias, err)
+			continue
+		}
+		if verbose && len(removed) > 0 {
+			fmt.Printf("  %s: removed %d old backup(s)\n", project.Alias, len(removed))
+		}
+	}
+}
+
+// pruneBackups removes the oldest timestamped backups in claudeDir/bk so that at most keep remain.
+// Other entries in bk are left alone. Returns the removed backup directory paths.
+func pruneBackups(claudeDir string, keep int) ([]string, error) {
+	bkDir := filepath.Join(claudeDir, "bk")
+	if !utils.IsDirectory(bkDir) {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(bkDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	// Timestamped names sort chronologically
+	var backups []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(backupTimestampFormat, entry.Name()); err == nil {
+			backups = append(backups, entry.Name())
+		}
+	}
+	sort.Strings(backups)
+
+	if len(backups) <= keep {
+		return nil, nil
+	}
+
+	var removed []string
+	for _, name := range backups[:len(backups)-keep] {
+		path := filepath.Join(bkDir, name)
+		if err := utils.RemoveDir(path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, path)
+	}
+
+	return removed, nil
+}
@@ -476,3 +476,63 @@ func TestBackupExcludesBkDirectory(t *testing.T) {
 		t.Error("Old backup file should still exist")
 	}
 }
+
+func TestPruneBackups(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, ".claude")
+
+	names := []string{"20250101-100000", "20250102-100000", "20250103-100000", "20250104-100000"}
+	others := []string{"0-manual", "archive", "20250101"} // Not created by backup
+	for _, name := range append(append([]string{}, names...), others...) {
+		if err := os.MkdirAll(filepath.Join(projectDir, "bk", name), 0755); err != nil {
+			t.Fatalf("Failed to create backup directory: %v", err)
+		}
+	}
+
+	t.Run("keeps newest backups", func(t *testing.T) {
+		removed, err := pruneBackups(projectDir, 2)
+		if err != nil {
+			t.Fatalf("pruneBackups failed: %v", err)
+		}
+
+		if len(removed) != 2 {
+			t.Errorf("Expected 2 removed backups, got %d", len(removed))
+		}
+
+		for _, name := range names[:2] {
+			if utils.FileExists(filepath.Join(projectDir, "bk", name)) {
+				t.Errorf("Old backup %s should be removed", name)
+			}
+		}
+		for _, name := range names[2:] {
+			if !utils.FileExists(filepath.Join(projectDir, "bk", name)) {
+				t.Errorf("Recent backup %s should be kept", name)
+			}
+		}
+		for _, name := range others {
+			if !utils.FileExists(filepath.Join(projectDir, "bk", name)) {
+				t.Errorf("Directory %s was not created by backup and should be kept", name)
+			}
+		}
+	})
+
+	t.Run("nothing to prune", func(t *testing.T) {
+		removed, err := pruneBackups(projectDir, 5)
+		if err != nil {
+			t.Fatalf("pruneBackups failed: %v", err)
+		}
+		if len(removed) != 0 {
+			t.Errorf("Expected no removed backups, got %d", len(removed))
+		}
+	})
+
+	t.Run("missing bk directory", func(t *testing.T) {
+		removed, err := pruneBackups(filepath.Join(tmpDir, "missing"), 1)
+		if err != nil {
+			t.Fatalf("pruneBackups failed: %v", err)
+		}
+		if len(removed) != 0 {
+			t.Errorf("Expected no removed backups, got %d", len(removed))
+		}
+	})
+}
@@ -2,15 +2,19 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/yugo-ibuki/dot-claude-sync/config"
+	"github.com/yugo-ibuki/dot-claude-sync/syncer"
+	"github.com/yugo-ibuki/dot-claude-sync/utils"
 )
 
 var configCmd = &cobra.Command{
@@ -22,9 +26,12 @@ var configCmd = &cobra.Command{
 var configShowCmd = &cobra.Command{
 	Use:   "show [group]",
 	Short: "Show configuration",
-	Long:  `Display the entire configuration or details of a specific group.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runConfigShow,
+	Long: `Display the entire configuration or details of a specific group.
+
+Use --origin to show which file each group was loaded from when the
+configuration includes other files.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigShow,
 }
 
 var configAddGroupCmd = &cobra.Command{
@@ -106,18 +113,102 @@ Argument mode (2+ arguments):
 	RunE: runConfigSetPriority,
 }
 
+var configRenameGroupCmd = &cobra.Command{
+	Use:   "rename-group <old-name> <new-name>",
+	Short: "Rename a group",
+	Long: `Rename a group. Groups that extend it and a default_group that names it are
+updated to the new name.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runConfigRenameGroup,
+}
+
+var configRenameProjectCmd = &cobra.Command{
+	Use:   "rename-project <group> <old-alias> <new-alias>",
+	Short: "Rename a project in a group",
+	Long: `Rename a project in a group. Its priority entry and path mappings are updated
+to the new alias.`,
+	Args: cobra.ExactArgs(3),
+	RunE: runConfigRenameProject,
+}
+
+var configMoveProjectCmd = &cobra.Command{
+	Use:   "move-project <from-group> <to-group> <alias>",
+	Short: "Move a project to another group",
+	Long: `Move a project, with its path mappings, from one group to another.
+The project keeps its alias and ranks after the target group's prioritized projects.`,
+	Args: cobra.ExactArgs(3),
+	RunE: runConfigMoveProject,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration",
+	Long: `Check the configuration and every file it includes for problems: unknown keys,
+invalid exclude/include/rule patterns, project paths that do not exist or are not
+directories, priority entries that name no project, and projects of a group that
+resolve to the same directory.
+
+Each problem is reported as file:line:column. Exits with an error if any are found.`,
+	Args:         cobra.NoArgs,
+	RunE:         runConfigValidate,
+	SilenceUsage: true, // Problems are listed already
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the configuration JSON Schema",
+	Long: `Print a JSON Schema for the configuration file, for editor validation and completion.
+
+Example (yaml-language-server):
+  dot-claude-sync config schema > ~/.config/dot-claude-sync/schema.json
+  # yaml-language-server: $schema=./schema.json`,
+	Args: cobra.NoArgs,
+	RunE: runConfigSchema,
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Convert the configuration to the current format",
+	Long: `Convert the configuration file (and any included files) to format version 2,
+where each group lists its projects with an alias, path and optional priority:
+
+  version: 2
+  groups:
+    web:
+      projects:
+        - alias: app
+          path: ~/projects/app
+          priority: 1
+        - alias: admin
+          path: ~/projects/admin
+
+Each file is backed up next to itself (<file>.<timestamp>.bak) before it is rewritten.
+Version 1 files keep working without migrating.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigMigrate,
+}
+
+var configShowOrigin bool // show the file each group was loaded from
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configShowCmd)
+	configShowCmd.Flags().BoolVar(&configShowOrigin, "origin", false, "show the file each group was loaded from")
 	configCmd.AddCommand(configAddGroupCmd)
 	configCmd.AddCommand(configRemoveGroupCmd)
 	configCmd.AddCommand(configAddProjectCmd)
 	configCmd.AddCommand(configRemoveProjectCmd)
 	configCmd.AddCommand(configSetPriorityCmd)
+	configCmd.AddCommand(configRenameGroupCmd)
+	configCmd.AddCommand(configRenameProjectCmd)
+	configCmd.AddCommand(configMoveProjectCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+	configCmd.AddCommand(configMigrateCmd)
 }
 
 func runConfigShow(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load(cfgFile)
+	cfg, err := config.LoadWithLocal(cfgFile)
 	if err != nil {
 		return err
 	}
@@ -127,6 +218,21 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 		fmt.Println("Configuration:")
 		fmt.Println()
 
+		fmt.Println("⚙ built-in excludes (never synced unless re-included or builtin_excludes: false)")
+		fmt.Printf("  %v\n", config.BuiltinExcludes)
+		fmt.Println()
+
+		if len(cfg.Include) > 0 {
+			fmt.Printf("⚙ include: %v\n", cfg.Include)
+			fmt.Println()
+		}
+
+		if cfg.Defaults != nil {
+			fmt.Println("⚙ defaults")
+			printSettings(defaultsSettings(cfg.Defaults))
+			fmt.Println()
+		}
+
 		groups := cfg.ListGroups()
 		if len(groups) == 0 {
 			fmt.Println("No groups configured")
@@ -134,7 +240,11 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 		}
 
 		for _, groupName := range groups {
-			group, _ := cfg.GetGroup(groupName)
+			group, err := cfg.GetEffectiveGroup(groupName)
+			if err != nil {
+				fmt.Printf("⚠ Group '%s': %v\n", groupName, err)
+				continue
+			}
 			projects, err := group.GetProjectPaths()
 			if err != nil {
 				fmt.Printf("⚠ Group '%s': error parsing projects: %v\n", groupName, err)
@@ -142,15 +252,22 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 			}
 
 			fmt.Printf("📦 %s (%d projects)\n", groupName, len(projects))
+			if configShowOrigin {
+				fmt.Printf("  from %s\n", cfg.Origin(groupName))
+			}
 			for _, proj := range projects {
-				fmt.Printf("  [%d] %s → %s\n", proj.Priority, proj.Alias, proj.Path)
+				if proj.Layer > 0 {
+					fmt.Printf("  [%d] %s → %s (inherited)\n", proj.Priority, proj.Alias, proj.Path)
+				} else {
+					fmt.Printf("  [%d] %s → %s\n", proj.Priority, proj.Alias, proj.Path)
+				}
 			}
 			fmt.Println()
 		}
 	} else {
-		// Show specific group
+		// Show specific group (with defaults applied)
 		groupName := args[0]
-		group, err := cfg.GetGroup(groupName)
+		group, err := cfg.GetEffectiveGroup(groupName)
 		if err != nil {
 			return err
 		}
@@ -161,10 +278,20 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Printf("Group: %s\n", groupName)
+		if configShowOrigin {
+			fmt.Printf("Origin: %s\n", cfg.Origin(groupName))
+		}
+		if len(group.Extends) > 0 {
+			fmt.Printf("Extends: %v\n", group.Extends)
+		}
 		fmt.Println()
 		fmt.Printf("Projects (%d):\n", len(projects))
 		for _, proj := range projects {
-			fmt.Printf("  [%d] %s\n", proj.Priority, proj.Alias)
+			if proj.Layer > 0 {
+				fmt.Printf("  [%d] %s (inherited)\n", proj.Priority, proj.Alias)
+			} else {
+				fmt.Printf("  [%d] %s\n", proj.Priority, proj.Alias)
+			}
 			fmt.Printf("      %s\n", proj.Path)
 		}
 
@@ -172,11 +299,145 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 			fmt.Println()
 			fmt.Printf("Priority: %v\n", group.Priority)
 		}
+
+		for _, proj := range projects {
+			if len(proj.Mappings) == 0 {
+				continue
+			}
+			fmt.Println()
+			fmt.Printf("Mappings (%s):\n", proj.Alias)
+			for _, mapping := range proj.Mappings {
+				fmt.Printf("  %s\n", mapping)
+			}
+		}
+
+		fmt.Println()
+		fmt.Println("Settings (including built-in excludes and defaults):")
+		printSettings(groupSettings(group))
 	}
 
 	return nil
 }
 
+// settingsView holds the sync settings shared by defaults and groups, as shown by config show
+type settingsView struct {
+	Dir             string
+	Exclude         []string
+	Include         []string
+	BuiltinExcludes *bool // nil when not set
+	RootFiles       []string
+	MaxFileSize     string
+	Binary          string
+	Strategy        string
+	Rules           []config.Rule
+	Folders         []string
+	Vars            map[string]string
+	Overlay         string
+	SecretPatterns  []string
+	Backup          *config.BackupConfig
+}
+
+// defaultsSettings returns the settings of the defaults section
+func defaultsSettings(d *config.Defaults) settingsView {
+	return settingsView{
+		Dir:             d.Dir,
+		Exclude:         d.Exclude,
+		Include:         d.Include,
+		BuiltinExcludes: d.BuiltinExcludes,
+		RootFiles:       d.RootFiles,
+		MaxFileSize:     d.MaxFileSize,
+		Binary:          d.Binary,
+		Strategy:        d.Strategy,
+		Rules:           d.Rules,
+		Folders:         d.Folders,
+		Vars:            d.Vars,
+		Overlay:         d.Overlay,
+		SecretPatterns:  d.SecretPatterns,
+		Backup:          d.Backup,
+	}
+}
+
+// groupSettings returns the settings of an effective group
+func groupSettings(g *config.Group) settingsView {
+	builtinExcludes := g.UsesBuiltinExcludes()
+	return settingsView{
+		Dir:             g.Dir,
+		Exclude:         g.Exclude,
+		Include:         g.Include,
+		BuiltinExcludes: &builtinExcludes,
+		RootFiles:       g.RootFiles,
+		MaxFileSize:     g.MaxFileSize,
+		Binary:          g.Binary,
+		Strategy:        g.Strategy,
+		Rules:           g.Rules,
+		Folders:         g.Folders,
+		Vars:            g.Vars,
+		Overlay:         g.Overlay,
+		SecretPatterns:  g.SecretPatterns,
+		Backup:          g.Backup,
+	}
+}
+
+// printSettings prints sync settings shared by defaults and groups
+func printSettings(settings settingsView) {
+	strategy := settings.Strategy
+	if strategy == "" {
+		strategy = "newest"
+	}
+	fmt.Printf("  Strategy: %s\n", strategy)
+	if settings.Dir != "" {
+		fmt.Printf("  Dir: %s\n", settings.Dir)
+	}
+	if len(settings.Exclude) > 0 {
+		fmt.Printf("  Exclude: %v\n", settings.Exclude)
+	}
+	if settings.BuiltinExcludes != nil {
+		fmt.Printf("  Built-in excludes: %t\n", *settings.BuiltinExcludes)
+	}
+	if len(settings.Include) > 0 {
+		fmt.Printf("  Include: %v\n", settings.Include)
+	}
+	if len(settings.Folders) > 0 {
+		fmt.Printf("  Folders: %v\n", settings.Folders)
+	}
+	if len(settings.RootFiles) > 0 {
+		fmt.Printf("  Root files: %v\n", settings.RootFiles)
+	}
+	if settings.MaxFileSize != "" {
+		fmt.Printf("  Max file size: %s\n", settings.MaxFileSize)
+	}
+	if settings.Binary != "" {
+		fmt.Printf("  Binary files: %s\n", settings.Binary)
+	}
+	if len(settings.SecretPatterns) > 0 {
+		fmt.Printf("  Secret patterns: %v\n", settings.SecretPatterns)
+	}
+	if settings.Overlay != "" {
+		fmt.Printf("  Overlay: %s\n", settings.Overlay)
+	}
+	if settings.Backup != nil {
+		fmt.Printf("  Backup: keep=%d\n", settings.Backup.Keep)
+	}
+	if len(settings.Rules) > 0 {
+		fmt.Println("  Rules:")
+		for _, rule := range settings.Rules {
+			fmt.Printf("    %s → %s\n", rule.Pattern, rule.Strategy)
+		}
+	}
+	if len(settings.Vars) > 0 {
+		names := make([]string, 0, len(settings.Vars))
+		for name := range settings.Vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Println("  Vars:")
+		for _, name := range names {
+			fmt.Printf("    %s = %s\n", name, settings.Vars[name])
+		}
+	}
+}
+
 func runConfigAddGroup(cmd *cobra.Command, args []string) error {
 	groupName := args[0]
 
@@ -227,8 +488,7 @@ func runConfigRemoveGroup(cmd *cobra.Command, args []string) error {
 		fmt.Println("Available groups:")
 		fmt.Println()
 		for i, name := range groupNames {
-			group, _ := cfg.GetGroup(name)
-			projects, _ := group.GetProjectPaths()
+			projects, _ := ownProjects(cfg, name)
 			fmt.Printf("  %d. %s (%d projects)\n", i+1, name, len(projects))
 		}
 		fmt.Println()
@@ -259,12 +519,11 @@ func runConfigRemoveGroup(cmd *cobra.Command, args []string) error {
 
 	// Confirm deletion
 	if !force {
-		group, err := cfg.GetGroup(groupName)
-		if err != nil {
+		if _, err := cfg.GetGroup(groupName); err != nil {
 			return err
 		}
 
-		projects, _ := group.GetProjectPaths()
+		projects, _ := ownProjects(cfg, groupName)
 		fmt.Printf("This will remove group '%s' with %d projects.\n", groupName, len(projects))
 		fmt.Print("Continue? [y/N]: ")
 
@@ -450,8 +709,7 @@ func runConfigRemoveProject(cmd *cobra.Command, args []string) error {
 		fmt.Println("Available groups:")
 		fmt.Println()
 		for i, name := range groupNames {
-			group, _ := cfg.GetGroup(name)
-			projects, _ := group.GetProjectPaths()
+			projects, _ := ownProjects(cfg, name)
 			fmt.Printf("  %d. %s (%d projects)\n", i+1, name, len(projects))
 		}
 		fmt.Println()
@@ -473,16 +731,11 @@ func runConfigRemoveProject(cmd *cobra.Command, args []string) error {
 		groupName = groupNames[idx-1]
 
 		// Step 2: List projects in selected group
-		group, err := cfg.GetGroup(groupName)
+		projects, err := ownProjects(cfg, groupName)
 		if err != nil {
 			return err
 		}
 
-		projects, err := group.GetProjectPaths()
-		if err != nil {
-			return fmt.Errorf("failed to get project paths: %w", err)
-		}
-
 		if len(projects) == 0 {
 			fmt.Printf("Group '%s' has no projects\n", groupName)
 			return nil
@@ -566,8 +819,7 @@ func runConfigSetPriority(cmd *cobra.Command, args []string) error {
 		fmt.Println("Available groups:")
 		fmt.Println()
 		for i, name := range groupNames {
-			group, _ := cfg.GetGroup(name)
-			projects, _ := group.GetProjectPaths()
+			projects, _ := ownProjects(cfg, name)
 			fmt.Printf("  %d. %s (%d projects)\n", i+1, name, len(projects))
 		}
 		fmt.Println()
@@ -589,16 +841,11 @@ func runConfigSetPriority(cmd *cobra.Command, args []string) error {
 		groupName = groupNames[idx-1]
 
 		// Step 2: List projects in selected group
-		group, err := cfg.GetGroup(groupName)
+		projects, err := ownProjects(cfg, groupName)
 		if err != nil {
 			return err
 		}
 
-		projects, err := group.GetProjectPaths()
-		if err != nil {
-			return fmt.Errorf("failed to get project paths: %w", err)
-		}
-
 		if len(projects) == 0 {
 			fmt.Printf("Group '%s' has no projects\n", groupName)
 			return nil
@@ -684,3 +931,186 @@ func runConfigSetPriority(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+func runConfigRenameGroup(cmd *cobra.Command, args []string) error {
+	oldName, newName := args[0], args[1]
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.RenameGroup(oldName, newName); err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("DRY RUN: Would rename group '%s' to '%s'\n", oldName, newName)
+		return nil
+	}
+
+	if err := cfg.Save(cfgFile); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("✓ Renamed group '%s' to '%s'\n", oldName, newName)
+	return nil
+}
+
+func runConfigRenameProject(cmd *cobra.Command, args []string) error {
+	groupName, oldAlias, newAlias := args[0], args[1], args[2]
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.RenameProject(groupName, oldAlias, newAlias); err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("DRY RUN: Would rename project '%s' to '%s' in group '%s'\n", oldAlias, newAlias, groupName)
+		return nil
+	}
+
+	if err := cfg.Save(cfgFile); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("✓ Renamed project '%s' to '%s' in group '%s'\n", oldAlias, newAlias, groupName)
+	return nil
+}
+
+func runConfigMoveProject(cmd *cobra.Command, args []string) error {
+	fromGroup, toGroup, alias := args[0], args[1], args[2]
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.MoveProject(fromGroup, toGroup, alias); err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("DRY RUN: Would move project '%s' from group '%s' to '%s'\n", alias, fromGroup, toGroup)
+		return nil
+	}
+
+	if err := cfg.Save(cfgFile); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("✓ Moved project '%s' from group '%s' to '%s'\n", alias, fromGroup, toGroup)
+	return nil
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.LoadWithLocal(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	problems, err := cfg.Validate(func(pattern string) error {
+		_, err := syncer.NewMatcher([]string{pattern})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		fmt.Println("✓ Configuration is valid")
+		return nil
+	}
+
+	for _, problem := range problems {
+		fmt.Println(problem.Error())
+	}
+	return fmt.Errorf("found %d problem(s) in the configuration", len(problems))
+}
+
+func runConfigSchema(cmd *cobra.Command, args []string) error {
+	strategies := make([]string, 0, len(syncer.Strategies))
+	for _, strategy := range syncer.Strategies {
+		strategies = append(strategies, string(strategy))
+	}
+
+	schema := config.Schema(map[string][]string{
+		"strategy": strategies,
+		"binary":   {string(syncer.BinarySync), string(syncer.BinarySkip), string(syncer.BinaryWarn)},
+	})
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	files := cfg.Files()
+	changed, err := cfg.Migrate()
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Printf("✓ Configuration is already at version %d\n", config.CurrentVersion)
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("[DRY RUN] Would migrate to version %d:\n", config.CurrentVersion)
+		for _, file := range files {
+			fmt.Printf("  %s\n", file)
+		}
+		return nil
+	}
+
+	timestamp := time.Now().Format(backupTimestampFormat)
+	for _, file := range files {
+		backupPath := fmt.Sprintf("%s.%s.bak", file, timestamp)
+		if err := utils.CopyFile(file, backupPath); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", file, err)
+		}
+		fmt.Printf("✓ Backed up %s to %s\n", file, backupPath)
+	}
+
+	if err := cfg.Save(cfgFile); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Migrated configuration to version %d\n", config.CurrentVersion)
+	return nil
+}
+
+// ownProjects returns the resolved projects of a group with its dir and defaults applied,
+// leaving out projects inherited through extends, which are edited in their own group
+func ownProjects(cfg *config.Config, groupName string) ([]config.ProjectPath, error) {
+	group, err := cfg.GetEffectiveGroup(groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := group.GetProjectPaths()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project paths: %w", err)
+	}
+
+	var own []config.ProjectPath
+	for _, project := range projects {
+		if project.Layer == 0 {
+			own = append(own, project)
+		}
+	}
+	return own, nil
+}
@@ -312,3 +312,121 @@ func TestRunConfigRemoveProject(t *testing.T) {
 		}
 	})
 }
+
+func TestRunConfigMoveProject(t *testing.T) {
+	// Save original global variables
+	origCfgFile := cfgFile
+	origDryRun := dryRun
+	defer func() {
+		cfgFile = origCfgFile
+		dryRun = origDryRun
+	}()
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `groups:
+  web:
+    paths:
+      app: /path/app/.claude
+      admin: /path/admin/.claude
+    priority: [app, admin]
+  api:
+    paths:
+      server: /path/server/.claude
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+	cfgFile = configPath
+
+	t.Run("dry-run does not modify config", func(t *testing.T) {
+		dryRun = true
+		if err := runConfigMoveProject(nil, []string{"web", "api", "app"}); err != nil {
+			t.Fatalf("runConfigMoveProject failed: %v", err)
+		}
+
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != configContent {
+			t.Errorf("Config should be unchanged in dry-run mode:\n%s", data)
+		}
+	})
+
+	t.Run("moves project between groups", func(t *testing.T) {
+		dryRun = false
+		if err := runConfigMoveProject(nil, []string{"web", "api", "app"}); err != nil {
+			t.Fatalf("runConfigMoveProject failed: %v", err)
+		}
+
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		web, _ := cfg.Groups["web"].GetProjectPaths()
+		api, _ := cfg.Groups["api"].GetProjectPaths()
+		if len(web) != 1 || len(api) != 2 {
+			t.Errorf("Expected 1 project in web and 2 in api, got %d and %d", len(web), len(api))
+		}
+		if len(cfg.Groups["web"].Priority) != 1 {
+			t.Errorf("Priority entry should be removed from web, got %v", cfg.Groups["web"].Priority)
+		}
+	})
+
+	t.Run("rename project and group", func(t *testing.T) {
+		if err := runConfigRenameProject(nil, []string{"api", "app", "frontend"}); err != nil {
+			t.Fatalf("runConfigRenameProject failed: %v", err)
+		}
+		if err := runConfigRenameGroup(nil, []string{"api", "backend"}); err != nil {
+			t.Fatalf("runConfigRenameGroup failed: %v", err)
+		}
+
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		group, err := cfg.GetGroup("backend")
+		if err != nil {
+			t.Fatalf("Renamed group not found: %v", err)
+		}
+		if aliases := group.PathAliases(); len(aliases) != 2 || aliases[1] != "frontend" {
+			t.Errorf("Expected aliases [server frontend], got %v", aliases)
+		}
+	})
+}
+
+func TestOwnProjects(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	content := `defaults:
+  dir: .cursor
+groups:
+  base:
+    paths: [/work/shared]
+  web:
+    extends: [base]
+    paths: [/work/a, /work/b]
+`
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	// The group's dir applies and inherited projects are left out
+	projects, err := ownProjects(cfg, "web")
+	if err != nil {
+		t.Fatalf("ownProjects failed: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("Expected the group's 2 own projects, got %+v", projects)
+	}
+	for _, project := range projects {
+		if filepath.Base(project.Path) != ".cursor" {
+			t.Errorf("%s: expected a .cursor path, got %s", project.Alias, project.Path)
+		}
+	}
+}
@@ -9,21 +9,49 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 
 	"github.com/yugo-ibuki/dot-claude-sync/config"
+	"github.com/yugo-ibuki/dot-claude-sync/syncer"
+	"github.com/yugo-ibuki/dot-claude-sync/utils"
 )
 
 var detectCmd = &cobra.Command{
 	Use:   "detect <worktree-root> --group <group-name>",
 	Short: "Detect .claude directories in git worktrees",
 	Long: `Automatically detect .claude directories from git worktrees and add them to a group.
-This command runs 'git worktree list' in the specified directory and finds all .claude directories.`,
+This command runs 'git worktree list' in the specified directory and finds all .claude directories.
+A group that syncs another directory (dir, set on the group or in defaults) detects that one instead.
+
+With --scan the directory tree is searched instead, up to --depth levels below the directory,
+so that a folder of independent repositories or plain directories can be added. node_modules,
+vendor and .git directories are not searched.
+
+With --create, worktrees without the synced directory (e.g., right after 'git worktree add')
+are added too and the directory is created. Add --seed to fill the created
+directories with the group's resolved files, as push would.
+
+With --refresh the group is reconciled with the worktrees instead: worktrees that are not in
+the group yet are added with an alias derived from their branch, and projects of deleted
+worktrees (or whose directory no longer exists) are removed. Existing aliases and priorities
+are kept. The changes are shown before the configuration is saved.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runDetect,
 }
 
 var groupName string
+var detectRefresh bool // reconcile the group with the worktrees
+var detectScan bool    // search the directory tree instead of git worktrees
+var detectDepth int    // levels below the directory searched by --scan
+var detectCreate bool  // add worktrees without the synced directory and create it
+var detectSeed bool    // sync the group's files into created .claude directories
+
+// scanSkipDirs are directories never searched by --scan
+var scanSkipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	".venv":        true,
+}
 
 func init() {
 	rootCmd.AddCommand(detectCmd)
@@ -31,20 +59,20 @@ func init() {
 	if err := detectCmd.MarkFlagRequired("group"); err != nil {
 		panic(fmt.Sprintf("failed to mark flag as required: %v", err))
 	}
+	detectCmd.Flags().BoolVar(&detectRefresh, "refresh", false, "add new worktrees and remove deleted ones from the group")
+	detectCmd.Flags().BoolVar(&detectScan, "scan", false, "search the directory tree for .claude directories instead of git worktrees")
+	detectCmd.Flags().IntVar(&detectDepth, "depth", 3, "levels below the directory searched by --scan")
+	detectCmd.Flags().BoolVar(&detectCreate, "create", false, "also add worktrees without the synced directory and create it")
+	detectCmd.Flags().BoolVar(&detectSeed, "seed", false, "sync the group's files into created directories (with --create)")
 }
 
 func runDetect(cmd *cobra.Command, args []string) error {
-	worktreeRoot := args[0]
-
-	// Expand ~ to home directory
-	if strings.HasPrefix(worktreeRoot, "~") {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("failed to get home directory: %w", err)
-		}
-		worktreeRoot = filepath.Join(homeDir, worktreeRoot[1:])
+	if detectSeed && !detectCreate {
+		return fmt.Errorf("--seed requires --create")
 	}
 
+	worktreeRoot := utils.ExpandPath(args[0])
+
 	// Resolve to absolute path
 	absRoot, err := filepath.Abs(worktreeRoot)
 	if err != nil {
@@ -56,39 +84,67 @@ func runDetect(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("directory does not exist: %s", absRoot)
 	}
 
-	// Execute git worktree list
-	worktrees, err := getWorktreePaths(absRoot)
+	// Load existing config, which determines the directory synced in each project
+	cfg, err := loadOrCreateConfig()
 	if err != nil {
-		return fmt.Errorf("failed to get worktree list: %w", err)
+		return err
+	}
+	dir, err := syncedDir(cfg, groupName)
+	if err != nil {
+		return err
 	}
 
-	if len(worktrees) == 0 {
+	worktrees, err := findProjects(absRoot, dir)
+	if err != nil {
+		return err
+	}
+
+	if detectRefresh {
+		return runDetectRefresh(cfg, absRoot, worktrees)
+	}
+
+	if len(worktrees) == 0 && !detectScan {
 		fmt.Println("No worktrees found.")
 		return nil
 	}
 
-	// Detect .claude directories
+	// Detect synced directories
 	claudeDirs := []string{}
+	missing := make(map[string]bool) // Directories created with --create
 	for _, wt := range worktrees {
-		claudeDir := filepath.Join(wt, ".claude")
+		claudeDir := filepath.Join(wt.Path, dir)
 		if info, err := os.Stat(claudeDir); err == nil && info.IsDir() {
 			claudeDirs = append(claudeDirs, claudeDir)
+		} else if detectCreate && utils.IsDirectory(wt.Path) {
+			claudeDirs = append(claudeDirs, claudeDir)
+			missing[claudeDir] = true
 		}
 	}
 
 	if len(claudeDirs) == 0 {
-		fmt.Println("No .claude directories found in worktrees.")
+		if detectScan {
+			fmt.Printf("No %s directories found in %s (depth %d).\n", dir, absRoot, detectDepth)
+		} else {
+			fmt.Printf("No %s directories found in worktrees.\n", dir)
+		}
 		return nil
 	}
 
 	// Display detected paths
-	fmt.Printf("Found %d .claude director%s:\n", len(claudeDirs), pluralize(len(claudeDirs)))
+	fmt.Printf("Found %d %s director%s:\n", len(claudeDirs), dir, pluralize(len(claudeDirs)))
 	for i, dir := range claudeDirs {
-		fmt.Printf("  %d. %s\n", i+1, dir)
+		if missing[dir] {
+			fmt.Printf("  %d. %s (will be created)\n", i+1, dir)
+		} else {
+			fmt.Printf("  %d. %s\n", i+1, dir)
+		}
 	}
 
 	if dryRun {
 		fmt.Printf("\nDRY RUN: Would add these paths to group '%s'\n", groupName)
+		if detectSeed && len(missing) > 0 {
+			fmt.Printf("DRY RUN: Would seed %d created director%s with the group's files\n", len(missing), pluralize(len(missing)))
+		}
 		return nil
 	}
 
@@ -104,23 +160,26 @@ func runDetect(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Load existing config
-	cfg, err := loadOrCreateConfig()
-	if err != nil {
-		return err
-	}
-
 	// Add paths to group
 	if err := addPathsToGroup(cfg, groupName, claudeDirs); err != nil {
 		return err
 	}
 
-	// Save config
+	// Save config before creating anything, so a failed save leaves no stray directories
 	if err := saveConfig(cfg); err != nil {
 		return err
 	}
 
+	if err := createDirs(missing); err != nil {
+		return err
+	}
+
 	fmt.Printf("\n✓ Added %d path%s to group '%s'\n", len(claudeDirs), pluralize(len(claudeDirs)), groupName)
+
+	if detectSeed && len(missing) > 0 {
+		return seedProjects(cfg, groupName, missing)
+	}
+
 	fmt.Println("\nNext steps:")
 	fmt.Printf("  1. Review configuration: dot-claude-sync list %s\n", groupName)
 	fmt.Printf("  2. Sync files: dot-claude-sync push %s\n", groupName)
@@ -128,8 +187,332 @@ func runDetect(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// getWorktreePaths executes 'git worktree list --porcelain' and returns worktree paths
-func getWorktreePaths(rootDir string) ([]string, error) {
+// createDirs creates the synced directories of worktrees added with --create
+func createDirs(dirs map[string]bool) error {
+	for dir := range dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+		if verbose {
+			fmt.Printf("Created %s\n", dir)
+		}
+	}
+	return nil
+}
+
+// seedProjects syncs the group's resolved files into the given synced directories only.
+// Files are collected and resolved as push does; other projects are not written to.
+func seedProjects(cfg *config.Config, groupName string, dirs map[string]bool) error {
+	group, err := cfg.GetEffectiveGroup(groupName)
+	if err != nil {
+		return err
+	}
+	projects, err := group.GetProjectPaths()
+	if err != nil {
+		return fmt.Errorf("failed to parse group paths: %w", err)
+	}
+	opts, err := parsePushOptions(group)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nSeeding %d created director%s from group '%s'...\n", len(dirs), pluralize(len(dirs)), groupName)
+
+	allFiles, skipped, err := syncer.CollectFilesWithReport(projects, opts.collect)
+	printSkippedFiles(skipped)
+	if err != nil {
+		return fmt.Errorf("failed to collect files: %w", err)
+	}
+	if len(allFiles) == 0 {
+		fmt.Println("No files to seed")
+		return nil
+	}
+
+	resolved, _, err := syncer.ResolveConflictsWithOptions(allFiles, syncer.ResolveOptions{
+		Strategy: opts.strategy,
+		Folders:  group.Folders,
+		Rules:    opts.rules,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve conflicts: %w", err)
+	}
+	if err := checkSecrets(resolved, opts.secretRules); err != nil {
+		return err
+	}
+
+	var targets []config.ProjectPath
+	for _, project := range projects {
+		if project.Layer == 0 && dirs[filepath.Clean(project.Path)] {
+			targets = append(targets, project)
+		}
+	}
+
+	results, err := syncer.SyncFilesWithOptions(resolved, targets, syncer.SyncOptions{
+		Verbose: verbose,
+		Force:   force,
+		Vars:    group.Vars,
+		Overlay: opts.collect.Overlay,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to seed files: %w", err)
+	}
+
+	if !verbose {
+		syncer.PrintSyncResults(results, verbose)
+	}
+	fmt.Print(syncer.GetSyncSummary(results))
+
+	if syncer.HasErrors(results) {
+		return fmt.Errorf("some seed operations failed")
+	}
+	return nil
+}
+
+// worktree is a git worktree
+type worktree struct {
+	Path     string
+	Branch   string // Checked out branch; empty for a detached HEAD
+	Prunable bool   // Directory is gone but git still lists the worktree
+}
+
+// projectChange is a project added to or removed from a group
+type projectChange struct {
+	Alias string
+	Path  string
+}
+
+// findProjects returns the git worktrees of the repository at root, or with --scan the
+// directories below root that contain dir (e.g., .claude)
+func findProjects(root, dir string) ([]worktree, error) {
+	if !detectScan {
+		worktrees, err := getWorktrees(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get worktree list: %w", err)
+		}
+		return worktrees, nil
+	}
+
+	if detectDepth < 0 {
+		return nil, fmt.Errorf("invalid depth %d: must not be negative", detectDepth)
+	}
+	roots, err := scanProjectRoots(root, dir, detectDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]worktree, 0, len(roots))
+	for _, projectRoot := range roots {
+		projects = append(projects, worktree{Path: projectRoot})
+	}
+	return projects, nil
+}
+
+// scanProjectRoots returns the directories containing dir (e.g., .claude), from root down to
+// depth levels below it, in lexical order. Directories in scanSkipDirs and the synced
+// directories themselves are not searched.
+func scanProjectRoots(root, dir string, depth int) ([]string, error) {
+	top := strings.Split(filepath.ToSlash(dir), "/")[0] // e.g., .github for .github/prompts
+	var roots []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return filepath.SkipDir // Unreadable directory
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (scanSkipDirs[d.Name()] || d.Name() == top) {
+			return filepath.SkipDir
+		}
+
+		if utils.IsDirectory(filepath.Join(path, dir)) {
+			roots = append(roots, path)
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		level := 0
+		if rel != "." {
+			level = len(strings.Split(rel, string(filepath.Separator)))
+		}
+		if level >= depth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+
+	return roots, nil
+}
+
+// runDetectRefresh reconciles the group with the detected projects of root
+func runDetectRefresh(cfg *config.Config, root string, worktrees []worktree) error {
+	added, removed, converted, err := refreshGroup(cfg, groupName, root, worktrees)
+	if err != nil {
+		return err
+	}
+
+	if len(added) == 0 && len(removed) == 0 {
+		fmt.Printf("Group '%s' is up to date with the worktrees\n", groupName)
+		return nil
+	}
+
+	fmt.Printf("Changes to group '%s':\n", groupName)
+	if converted {
+		fmt.Println("  ~ paths: list converted to an alias map (aliases are kept)")
+	}
+	for _, change := range removed {
+		fmt.Printf("  - %s: %s\n", change.Alias, change.Path)
+	}
+	missing := make(map[string]bool) // Directories created with --create
+	for _, change := range added {
+		if utils.IsDirectory(change.Path) {
+			fmt.Printf("  + %s: %s\n", change.Alias, change.Path)
+		} else {
+			fmt.Printf("  + %s: %s (will be created)\n", change.Alias, change.Path)
+			missing[change.Path] = true
+		}
+	}
+
+	if dryRun {
+		fmt.Println("\nDRY RUN: Configuration not saved")
+		if detectSeed && len(missing) > 0 {
+			fmt.Printf("DRY RUN: Would seed %d created director%s with the group's files\n", len(missing), pluralize(len(missing)))
+		}
+		return nil
+	}
+
+	if !force {
+		fmt.Print("\nSave these changes? [y/N]: ")
+		reader := bufio.NewReader(os.Stdin)
+		response, _ := reader.ReadString('\n')
+		response = strings.TrimSpace(response)
+		if response != "y" && response != "Y" {
+			fmt.Println("Cancelled")
+			return nil
+		}
+	}
+
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	if err := createDirs(missing); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n✓ Refreshed group '%s': %d added, %d removed\n", groupName, len(added), len(removed))
+
+	if detectSeed && len(missing) > 0 {
+		return seedProjects(cfg, groupName, missing)
+	}
+	return nil
+}
+
+// refreshGroup adds worktrees with a synced directory (any worktree with --create) that are
+// not in the group yet and removes projects that are former worktrees of the repository at
+// root: deleted worktrees git still lists as prunable, or directories in the repository that
+// are no longer worktrees. Other missing projects (e.g., on an unmounted disk) are kept. Existing projects keep their aliases and priorities; a
+// list-format group is converted to an alias map so that new worktrees can be named after their
+// branch, and converted reports whether that happened.
+func refreshGroup(cfg *config.Config, groupName, root string, worktrees []worktree) (added, removed []projectChange, converted bool, err error) {
+	if _, exists := cfg.Groups[groupName]; !exists {
+		if err := cfg.AddGroup(groupName); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	group := cfg.Groups[groupName]
+
+	effective, err := cfg.GetEffectiveGroup(groupName)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if list, ok := group.Paths.([]interface{}); ok {
+		// Keep the aliases derived from the listed paths
+		projects, err := effective.GetProjectPaths()
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("failed to parse group paths: %w", err)
+		}
+		group.Paths = make(map[string]interface{})
+		converted = true
+		for i, item := range list {
+			if err := cfg.AddProject(groupName, projects[i].Alias, item.(string)); err != nil {
+				return nil, nil, false, err
+			}
+		}
+	}
+
+	current := make(map[string]bool)
+	prunable := make(map[string]bool)
+	for _, wt := range worktrees {
+		if wt.Prunable || !utils.IsDirectory(wt.Path) { // Deleted worktrees are listed until they are pruned
+			prunable[realPath(wt.Path)] = true
+		} else {
+			current[realPath(wt.Path)] = true
+		}
+	}
+
+	if effective, err = cfg.GetEffectiveGroup(groupName); err != nil {
+		return nil, nil, false, err
+	}
+	projects, err := effective.GetProjectPaths()
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to parse group paths: %w", err)
+	}
+
+	commonDir := utils.GitCommonDir(root)
+	kept := make(map[string]bool)
+	for _, project := range projects {
+		if project.Layer > 0 {
+			continue // Inherited from an extended group
+		}
+		projectRoot := realPath(project.Root)
+		stale := !current[projectRoot] && (prunable[projectRoot] ||
+			(commonDir != "" && utils.IsDirectory(project.Root) && utils.GitCommonDir(project.Root) == commonDir))
+		if !stale {
+			kept[projectRoot] = true
+			continue
+		}
+		if err := cfg.RemoveProject(groupName, project.Alias); err != nil {
+			return nil, nil, false, err
+		}
+		removed = append(removed, projectChange{Alias: project.Alias, Path: project.Path})
+	}
+
+	used := groupAliases(group)
+	for _, wt := range worktrees {
+		dir := filepath.Join(wt.Path, effective.ProjectDir())
+		if !current[realPath(wt.Path)] || kept[realPath(wt.Path)] || (!utils.IsDirectory(dir) && !detectCreate) {
+			continue
+		}
+		alias := config.UniqueAlias(worktreeAlias(wt.Path, wt.Branch), used)
+		used[alias] = true
+		if err := cfg.AddProject(groupName, alias, dir); err != nil {
+			return nil, nil, false, err
+		}
+		added = append(added, projectChange{Alias: alias, Path: dir})
+	}
+
+	return added, removed, converted, nil
+}
+
+// realPath returns path with symlinks resolved, or cleaned if it does not exist
+func realPath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// getWorktrees executes 'git worktree list --porcelain' and returns the worktrees
+func getWorktrees(rootDir string) ([]worktree, error) {
 	cmd := exec.Command("git", "-C", rootDir, "worktree", "list", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
@@ -141,20 +524,37 @@ func getWorktreePaths(rootDir string) ([]string, error) {
 	// worktree /path/to/worktree
 	// HEAD <commit>
 	// branch refs/heads/branch-name
+	// prunable <reason>          (only for deleted worktrees)
 	//
 	// worktree /path/to/another
 	// ...
-	var paths []string
+	var worktrees []worktree
 	scanner := bufio.NewScanner(strings.NewReader(string(output)))
 	for scanner.Scan() {
 		line := scanner.Text()
-		if strings.HasPrefix(line, "worktree ") {
-			path := strings.TrimPrefix(line, "worktree ")
-			paths = append(paths, path)
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			worktrees = append(worktrees, worktree{Path: strings.TrimPrefix(line, "worktree ")})
+		case strings.HasPrefix(line, "branch ") && len(worktrees) > 0:
+			worktrees[len(worktrees)-1].Branch = strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+		case (line == "prunable" || strings.HasPrefix(line, "prunable ")) && len(worktrees) > 0:
+			worktrees[len(worktrees)-1].Prunable = true
 		}
 	}
 
-	return paths, nil
+	return worktrees, nil
+}
+
+// worktreeAlias returns a project alias for the worktree at root: its branch name, or the
+// directory name for a detached HEAD. branch is looked up if empty.
+func worktreeAlias(root, branch string) string {
+	if branch == "" {
+		branch = utils.GitBranch(root)
+	}
+	if branch == "" {
+		return filepath.Base(root)
+	}
+	return strings.ReplaceAll(branch, "/", "-")
 }
 
 // loadOrCreateConfig loads existing config or creates a new one
@@ -176,10 +576,36 @@ func loadOrCreateConfig() (*config.Config, error) {
 // addPathsToGroup adds detected paths to the specified group
 func addPathsToGroup(cfg *config.Config, groupName string, paths []string) error {
 	group, exists := cfg.Groups[groupName]
-	if !exists {
-		// Create new group with simple list format
+	if !exists && cfg.Version >= 2 {
+		group = &config.Group{}
+		cfg.Groups[groupName] = group
+	} else if !exists {
+		// Create new group with simple list format, typed as it is when loaded
+		list := make([]interface{}, len(paths))
+		for i, p := range paths {
+			list[i] = p
+		}
 		cfg.Groups[groupName] = &config.Group{
-			Paths: paths,
+			Paths: list,
+		}
+		return nil
+	}
+
+	// Version 2 groups and alias maps get an alias for each worktree
+	_, isMap := group.Paths.(map[string]interface{})
+	if isMap || len(group.Projects) > 0 || (group.Paths == nil && cfg.Version >= 2) {
+		dir, err := syncedDir(cfg, groupName)
+		if err != nil {
+			return err
+		}
+		used := groupAliases(group)
+		for _, p := range paths {
+			root := strings.TrimSuffix(p, string(filepath.Separator)+dir)
+			alias := config.UniqueAlias(worktreeAlias(root, ""), used)
+			used[alias] = true
+			if err := cfg.AddProject(groupName, alias, p); err != nil {
+				return err
+			}
 		}
 		return nil
 	}
@@ -192,16 +618,6 @@ func addPathsToGroup(cfg *config.Config, groupName string, paths []string) error
 			existingPaths = append(existingPaths, p)
 		}
 		group.Paths = existingPaths
-	case map[string]interface{}:
-		// Convert to list and append
-		pathList := make([]interface{}, 0)
-		for _, v := range existingPaths {
-			pathList = append(pathList, v)
-		}
-		for _, p := range paths {
-			pathList = append(pathList, p)
-		}
-		group.Paths = pathList
 	case []string:
 		// Append to list (should not happen after YAML unmarshal, but handle it)
 		existingPaths = append(existingPaths, paths...)
@@ -218,6 +634,36 @@ func addPathsToGroup(cfg *config.Config, groupName string, paths []string) error
 	return nil
 }
 
+// syncedDir returns the directory the group syncs in each project (e.g., .claude). A group
+// that does not exist yet syncs the default dir.
+func syncedDir(cfg *config.Config, groupName string) (string, error) {
+	if _, exists := cfg.Groups[groupName]; !exists {
+		group := &config.Group{}
+		if cfg.Defaults != nil {
+			group.Dir = cfg.Defaults.Dir
+		}
+		return group.ProjectDir(), nil
+	}
+
+	group, err := cfg.GetEffectiveGroup(groupName)
+	if err != nil {
+		return "", err
+	}
+	return group.ProjectDir(), nil
+}
+
+// groupAliases returns the aliases of the group's own projects
+func groupAliases(group *config.Group) map[string]bool {
+	used := make(map[string]bool)
+	for _, project := range group.Projects {
+		used[project.Alias] = true
+	}
+	for _, alias := range group.PathAliases() {
+		used[alias] = true
+	}
+	return used
+}
+
 // saveConfig saves the configuration to file
 func saveConfig(cfg *config.Config) error {
 	homeDir, err := os.UserHomeDir()
@@ -238,15 +684,9 @@ func saveConfig(cfg *config.Config) error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	// Marshal config to YAML
-	data, err := yaml.Marshal(cfg)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
-
-	// Write to file
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	// Save keeps comments and key order of an existing file
+	if err := cfg.Save(configPath); err != nil {
+		return err
 	}
 
 	if verbose {
@@ -4,12 +4,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/yugo-ibuki/dot-claude-sync/config"
+	"github.com/yugo-ibuki/dot-claude-sync/utils"
 )
 
 // setupGitRepo creates a git repository with worktrees for testing
@@ -80,14 +82,18 @@ func setupGitRepo(t *testing.T, tmpDir string) (mainRepo string, worktrees []str
 	return mainRepo, worktrees
 }
 
-func TestGetWorktreePaths(t *testing.T) {
+func TestGetWorktrees(t *testing.T) {
 	tmpDir := t.TempDir()
 	mainRepo, expectedWorktrees := setupGitRepo(t, tmpDir)
 
 	// Test getting worktree paths
-	paths, err := getWorktreePaths(mainRepo)
+	worktrees, err := getWorktrees(mainRepo)
 	if err != nil {
-		t.Fatalf("getWorktreePaths failed: %v", err)
+		t.Fatalf("getWorktrees failed: %v", err)
+	}
+	var paths []string
+	for _, wt := range worktrees {
+		paths = append(paths, wt.Path)
 	}
 
 	// Should include main repo + worktrees
@@ -123,11 +129,11 @@ func TestGetWorktreePaths(t *testing.T) {
 	}
 }
 
-func TestGetWorktreePathsNonGitRepo(t *testing.T) {
+func TestGetWorktreesNonGitRepo(t *testing.T) {
 	tmpDir := t.TempDir()
 
 	// Try to get worktree paths from non-git directory
-	_, err := getWorktreePaths(tmpDir)
+	_, err := getWorktrees(tmpDir)
 	if err == nil {
 		t.Error("Expected error for non-git directory")
 	}
@@ -520,3 +526,335 @@ func TestLoadOrCreateConfig(t *testing.T) {
 		}
 	})
 }
+
+func TestRunDetectRefresh(t *testing.T) {
+	origCfgFile, origForce, origDryRun, origRefresh := cfgFile, force, dryRun, detectRefresh
+	defer func() {
+		cfgFile, force, dryRun, detectRefresh = origCfgFile, origForce, origDryRun, origRefresh
+	}()
+
+	tmpDir := t.TempDir()
+	mainRepo, worktrees := setupGitRepo(t, tmpDir)
+	for _, dir := range []string{mainRepo, worktrees[0], worktrees[1]} {
+		if err := os.MkdirAll(filepath.Join(dir, ".claude"), 0755); err != nil {
+			t.Fatalf("Failed to create .claude directory: %v", err)
+		}
+	}
+	other := filepath.Join(tmpDir, "other", ".claude")
+	if err := os.MkdirAll(other, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// feature-2 is deleted but still listed by git; "gone" never existed and can't be
+	// tied to the repository, so it is kept
+	if err := os.RemoveAll(worktrees[1]); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `groups:
+  test-group:
+    paths:
+      main: ` + filepath.Join(mainRepo, ".claude") + `
+      old-feature: ` + filepath.Join(worktrees[1], ".claude") + `
+      gone: ` + filepath.Join(tmpDir, "gone", ".claude") + `
+      other: ` + other + `
+    priority: [other, old-feature, main]
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfgFile = configPath
+	groupName = "test-group"
+	detectRefresh = true
+	force = true
+
+	dryRun = true
+	if err := runDetect(nil, []string{mainRepo}); err != nil {
+		t.Fatalf("runDetect --refresh --dry-run failed: %v", err)
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != configContent {
+		t.Fatalf("Config should be unchanged in dry-run mode:\n%s", data)
+	}
+
+	dryRun = false
+	if err := runDetect(nil, []string{mainRepo}); err != nil {
+		t.Fatalf("runDetect --refresh failed: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	group := cfg.Groups["test-group"]
+	if aliases := group.PathAliases(); !reflect.DeepEqual(aliases, []string{"main", "gone", "other", "feature-1"}) {
+		t.Errorf("Expected aliases [main gone other feature-1], got %v", aliases)
+	}
+	if !reflect.DeepEqual(group.Priority, []string{"other", "main"}) {
+		t.Errorf("Expected priorities of kept projects to stay, got %v", group.Priority)
+	}
+
+	// A second refresh has nothing to do
+	before, _ := os.ReadFile(configPath)
+	if err := runDetect(nil, []string{mainRepo}); err != nil {
+		t.Fatalf("second runDetect --refresh failed: %v", err)
+	}
+	if after, _ := os.ReadFile(configPath); string(after) != string(before) {
+		t.Errorf("Second refresh should not change the config:\n%s", after)
+	}
+}
+
+func TestRefreshGroup_ListConversion(t *testing.T) {
+	tmpDir := t.TempDir()
+	mainRepo, worktreePaths := setupGitRepo(t, tmpDir)
+	for _, dir := range []string{mainRepo, worktreePaths[0]} {
+		if err := os.MkdirAll(filepath.Join(dir, ".claude"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("groups:\n  test-group:\n    paths: ["+filepath.Join(mainRepo, ".claude")+"]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	worktrees, err := getWorktrees(mainRepo)
+	if err != nil {
+		t.Fatalf("getWorktrees failed: %v", err)
+	}
+
+	// The conversion is reported so that the preview can mention it
+	added, _, converted, err := refreshGroup(cfg, "test-group", mainRepo, worktrees)
+	if err != nil {
+		t.Fatalf("refreshGroup failed: %v", err)
+	}
+	if !converted || len(added) != 1 {
+		t.Errorf("Expected the list to be converted and one worktree added, got converted=%v added=%v", converted, added)
+	}
+	if _, ok := cfg.Groups["test-group"].Paths.(map[string]interface{}); !ok {
+		t.Errorf("Expected an alias map, got %T", cfg.Groups["test-group"].Paths)
+	}
+
+	if _, _, converted, err = refreshGroup(cfg, "test-group", mainRepo, worktrees); err != nil || converted {
+		t.Errorf("Expected an alias map not to be converted again, got converted=%v (%v)", converted, err)
+	}
+}
+
+func TestScanProjectRoots(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{
+		"app/.claude",
+		"app/.claude/nested/.claude", // Inside .claude, not a project
+		"group/api/.claude",
+		"group/web/node_modules/pkg/.claude",
+		"group/web/vendor/lib/.claude",
+		"a/b/c/deep/.claude",
+		"plain",
+	} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		depth    int
+		expected []string
+	}{
+		{0, nil},
+		{1, []string{"app"}},
+		{3, []string{"app", "group/api"}},
+		{4, []string{"a/b/c/deep", "app", "group/api"}},
+	}
+
+	for _, tt := range tests {
+		roots, err := scanProjectRoots(tmpDir, ".claude", tt.depth)
+		if err != nil {
+			t.Fatalf("scanProjectRoots(depth %d) failed: %v", tt.depth, err)
+		}
+		var rel []string
+		for _, root := range roots {
+			r, _ := filepath.Rel(tmpDir, root)
+			rel = append(rel, filepath.ToSlash(r))
+		}
+		if !reflect.DeepEqual(rel, tt.expected) {
+			t.Errorf("depth %d: expected %v, got %v", tt.depth, tt.expected, rel)
+		}
+	}
+}
+
+func TestScanProjectRoots_GroupDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{"cursor/.cursor", "claude/.claude", "prompts/.github/prompts"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for dir, expected := range map[string]string{".cursor": "cursor", ".github/prompts": "prompts"} {
+		roots, err := scanProjectRoots(tmpDir, dir, 2)
+		if err != nil {
+			t.Fatalf("scanProjectRoots(%s) failed: %v", dir, err)
+		}
+		if len(roots) != 1 || filepath.Base(roots[0]) != expected {
+			t.Errorf("%s: expected only %s, got %v", dir, expected, roots)
+		}
+	}
+}
+
+func TestRunDetectScan(t *testing.T) {
+	origCfgFile, origForce, origDryRun, origScan, origDepth := cfgFile, force, dryRun, detectScan, detectDepth
+	defer func() {
+		cfgFile, force, dryRun, detectScan, detectDepth = origCfgFile, origForce, origDryRun, origScan, origDepth
+	}()
+
+	tmpDir := t.TempDir()
+	projectsDir := filepath.Join(tmpDir, "projects")
+	for _, name := range []string{"api", "web"} {
+		if err := os.MkdirAll(filepath.Join(projectsDir, name, ".claude"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	cfgFile = configPath
+	groupName = "scanned"
+	force = true
+	dryRun = false
+	detectScan = true
+	detectDepth = 2
+
+	if err := runDetect(nil, []string{projectsDir}); err != nil {
+		t.Fatalf("runDetect --scan failed: %v", err)
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	projects, err := cfg.Groups["scanned"].GetProjectPaths()
+	if err != nil {
+		t.Fatalf("Failed to get project paths: %v", err)
+	}
+	if len(projects) != 2 || projects[0].Alias != "api" || projects[1].Alias != "web" {
+		t.Errorf("Expected projects api and web, got %+v", projects)
+	}
+}
+
+func TestRunDetectCreate(t *testing.T) {
+	origCfgFile, origForce, origDryRun, origCreate, origSeed := cfgFile, force, dryRun, detectCreate, detectSeed
+	defer func() {
+		cfgFile, force, dryRun, detectCreate, detectSeed = origCfgFile, origForce, origDryRun, origCreate, origSeed
+	}()
+
+	tmpDir := t.TempDir()
+	mainRepo, worktrees := setupGitRepo(t, tmpDir)
+	commandFile := filepath.Join(mainRepo, ".claude", "commands", "review.md")
+	if err := os.MkdirAll(filepath.Dir(commandFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(commandFile, []byte("# Review"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	cfgFile = configPath
+	groupName = "created"
+	force = true
+	dryRun = false
+
+	detectCreate, detectSeed = false, true
+	if err := runDetect(nil, []string{mainRepo}); err == nil || !strings.Contains(err.Error(), "--create") {
+		t.Errorf("Expected --seed to require --create, got %v", err)
+	}
+
+	detectCreate = true
+	if err := runDetect(nil, []string{mainRepo}); err != nil {
+		t.Fatalf("runDetect --create --seed failed: %v", err)
+	}
+
+	for _, worktree := range worktrees {
+		data, err := os.ReadFile(filepath.Join(worktree, ".claude", "commands", "review.md"))
+		if err != nil {
+			t.Errorf("Expected %s to be created and seeded: %v", worktree, err)
+		} else if string(data) != "# Review" {
+			t.Errorf("Unexpected seeded content in %s: %q", worktree, data)
+		}
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	projects, err := cfg.Groups["created"].GetProjectPaths()
+	if err != nil {
+		t.Fatalf("Failed to get project paths: %v", err)
+	}
+	if len(projects) != 3 {
+		t.Errorf("Expected the main repository and both worktrees in the group, got %+v", projects)
+	}
+}
+
+func TestRunDetectGroupDir(t *testing.T) {
+	origCfgFile, origForce, origDryRun, origCreate, origScan := cfgFile, force, dryRun, detectCreate, detectScan
+	defer func() {
+		cfgFile, force, dryRun, detectCreate, detectScan = origCfgFile, origForce, origDryRun, origCreate, origScan
+	}()
+
+	tmpDir := t.TempDir()
+	mainRepo, worktrees := setupGitRepo(t, tmpDir)
+	if err := os.MkdirAll(filepath.Join(mainRepo, ".cursor"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(worktrees[0], ".claude"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("defaults:\n  dir: .cursor\ngroups: {}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfgFile = configPath
+	groupName = "cursor"
+	force = true
+	dryRun = false
+	detectScan = false
+	detectCreate = true
+
+	if err := runDetect(nil, []string{mainRepo}); err != nil {
+		t.Fatalf("runDetect --create failed: %v", err)
+	}
+
+	for _, worktree := range worktrees {
+		if !utils.IsDirectory(filepath.Join(worktree, ".cursor")) {
+			t.Errorf("Expected .cursor to be created in %s", worktree)
+		}
+	}
+	if utils.IsDirectory(filepath.Join(worktrees[1], ".claude")) {
+		t.Error(".claude should not be created for a group syncing .cursor")
+	}
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	group, err := cfg.GetEffectiveGroup("cursor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	projects, err := group.GetProjectPaths()
+	if err != nil {
+		t.Fatalf("Failed to get project paths: %v", err)
+	}
+	if len(projects) != 3 {
+		t.Fatalf("Expected 3 projects, got %+v", projects)
+	}
+	for _, project := range projects {
+		if filepath.Base(project.Path) != ".cursor" {
+			t.Errorf("Expected a .cursor path, got %s", project.Path)
+		}
+	}
+}
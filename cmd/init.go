@@ -83,6 +83,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 
 	paths := make(map[string]string)
+	aliases := []string{} // Aliases in the order they were entered
 	pathList := []string{}
 	i := 1
 
@@ -100,6 +101,9 @@ func runInit(cmd *cobra.Command, args []string) error {
 		}
 
 		if alias != "" {
+			if _, exists := paths[alias]; !exists {
+				aliases = append(aliases, alias)
+			}
 			paths[alias] = path
 		} else {
 			pathList = append(pathList, path)
@@ -121,6 +125,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 			"project-b": "~/workspace/project-b/.claude",
 			"project-c": "~/workspace/project-c/.claude",
 		}
+		aliases = []string{"project-a", "project-b", "project-c"}
 	}
 
 	// Build group config
@@ -133,11 +138,7 @@ func runInit(cmd *cobra.Command, args []string) error {
 			fmt.Print("Set priority order? [y/N]: ")
 			var setPriority string
 			if _, err := fmt.Scanln(&setPriority); err == nil && (setPriority == "y" || setPriority == "Y") {
-				priority := make([]string, 0)
-				for alias := range paths {
-					priority = append(priority, alias)
-				}
-				group["priority"] = priority
+				group["priority"] = aliases
 				fmt.Println("Default priority order created. Edit config file to customize.")
 			}
 		}
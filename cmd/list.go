@@ -27,7 +27,7 @@ func runList(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Loading configuration...\n")
 	}
 
-	cfg, err := config.Load(cfgFile)
+	cfg, err := config.LoadWithLocal(cfgFile)
 	if err != nil {
 		return err
 	}
@@ -35,11 +35,14 @@ func runList(cmd *cobra.Command, args []string) error {
 	// If no group specified, list all groups
 	if len(args) == 0 {
 		groups := cfg.ListGroups()
-		sort.Strings(groups)
 
 		fmt.Println("Groups:")
 		for _, groupName := range groups {
-			group, _ := cfg.GetGroup(groupName)
+			group, err := cfg.GetEffectiveGroup(groupName)
+			if err != nil {
+				fmt.Printf("  %s (invalid: %v)\n", groupName, err)
+				continue
+			}
 			projects, _ := group.GetProjectPaths()
 			fmt.Printf("  %s (%d projects)\n", groupName, len(projects))
 		}
@@ -48,7 +51,7 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	// Show specific group details
 	groupName := args[0]
-	group, err := cfg.GetGroup(groupName)
+	group, err := cfg.GetEffectiveGroup(groupName)
 	if err != nil {
 		availableGroups := cfg.ListGroups()
 		return fmt.Errorf("%w\nAvailable groups: %v", err, availableGroups)
@@ -59,8 +62,8 @@ func runList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to parse group paths: %w", err)
 	}
 
-	// Sort by priority
-	sort.Slice(projects, func(i, j int) bool {
+	// Sort by priority, keeping declaration order for equal priorities
+	sort.SliceStable(projects, func(i, j int) bool {
 		return projects[i].Priority < projects[j].Priority
 	})
 
@@ -71,6 +74,9 @@ func runList(cmd *cobra.Command, args []string) error {
 		if project.Priority == len(projects) {
 			priorityLabel = "default priority"
 		}
+		if project.Layer > 0 {
+			priorityLabel += ", inherited"
+		}
 		fmt.Printf("  %d. %s: %s (%s)\n", project.Priority, project.Alias, project.Path, priorityLabel)
 	}
 
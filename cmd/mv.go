@@ -15,6 +15,8 @@ var mvCmd = &cobra.Command{
 	Use:   "mv <group> <from> <to>",
 	Short: "Move or rename a file or directory in all projects in a group",
 	Long: `Move or rename the specified file or directory in all projects in the group.
+Paths start with the group's synced directory (e.g., .claude/commands/foo.md).
+Projects inherited from extended groups are not changed.
 Prompts for confirmation unless --force is specified.`,
 	Args: cobra.ExactArgs(3),
 	RunE: runMv,
@@ -38,13 +40,28 @@ func runMv(cmd *cobra.Command, args []string) error {
 	rawFromPath := args[1]
 	rawToPath := args[2]
 
+	if verbose {
+		fmt.Printf("Loading configuration...\n")
+	}
+
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	group, err := cfg.GetEffectiveGroup(groupName)
+	if err != nil {
+		availableGroups := cfg.ListGroups()
+		return fmt.Errorf("%w\nAvailable groups: %v", err, availableGroups)
+	}
+
 	// Validate and normalize the paths
-	fromPath, err := utils.ValidateAndNormalizePath(rawFromPath)
+	fromPath, err := utils.ValidateAndNormalizePath(rawFromPath, group.ProjectDir())
 	if err != nil {
 		return fmt.Errorf("invalid source path: %w", err)
 	}
 
-	toPath, err := utils.ValidateAndNormalizePath(rawToPath)
+	toPath, err := utils.ValidateAndNormalizePath(rawToPath, group.ProjectDir())
 	if err != nil {
 		return fmt.Errorf("invalid destination path: %w", err)
 	}
@@ -58,21 +75,9 @@ func runMv(cmd *cobra.Command, args []string) error {
 	}
 
 	if verbose {
-		fmt.Printf("Loading configuration...\n")
 		fmt.Printf("Normalized paths: %s → %s\n", fromPath, toPath)
 	}
 
-	cfg, err := config.Load(cfgFile)
-	if err != nil {
-		return err
-	}
-
-	group, err := cfg.GetGroup(groupName)
-	if err != nil {
-		availableGroups := cfg.ListGroups()
-		return fmt.Errorf("%w\nAvailable groups: %v", err, availableGroups)
-	}
-
 	projects, err := group.GetProjectPaths()
 	if err != nil {
 		return fmt.Errorf("failed to parse group paths: %w", err)
@@ -83,7 +88,10 @@ func runMv(cmd *cobra.Command, args []string) error {
 	var notFoundProjects []config.ProjectPath
 
 	for _, project := range projects {
-		claudeDir := expandPath(project.Path)
+		if project.Layer > 0 {
+			continue // Inherited projects are not written to
+		}
+		claudeDir := utils.ExpandHome(project.Path)
 		srcFullPath := filepath.Join(claudeDir, fromPath)
 
 		if utils.FileExists(srcFullPath) {
@@ -187,7 +195,7 @@ func moveInProject(project config.ProjectPath, fromPath, toPath string, dryRun,
 		Project: project.Alias,
 	}
 
-	claudeDir := expandPath(project.Path)
+	claudeDir := utils.ExpandHome(project.Path)
 	srcFullPath := filepath.Join(claudeDir, fromPath)
 	dstFullPath := filepath.Join(claudeDir, toPath)
 
@@ -226,25 +234,3 @@ func moveInProject(project config.ProjectPath, fromPath, toPath string, dryRun,
 
 	return result
 }
-
-// expandPath expands ~ to home directory
-func expandPath(path string) string {
-	if len(path) == 0 || path[0] != '~' {
-		return path
-	}
-
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return path
-	}
-
-	if len(path) == 1 {
-		return homeDir
-	}
-
-	if path[1] == '/' || path[1] == filepath.Separator {
-		return filepath.Join(homeDir, path[2:])
-	}
-
-	return filepath.Join(homeDir, path[1:])
-}
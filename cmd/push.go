@@ -8,40 +8,65 @@ import (
 
 	"github.com/yugo-ibuki/dot-claude-sync/config"
 	"github.com/yugo-ibuki/dot-claude-sync/syncer"
+	"github.com/yugo-ibuki/dot-claude-sync/utils"
 )
 
 var pushCmd = &cobra.Command{
-	Use:   "push <group>",
+	Use:   "push [group]",
 	Short: "Sync .claude files across all projects in a group",
 	Long: `Collect .claude files from all projects in the specified group,
 resolve conflicts based on priority, and distribute to all projects.
 
+Without a group argument, the default group is used: the default_group (or only
+group) of the repository's .dot-claude-sync.yaml, or else the configured default_group.
+
 Use --folders to specify which folders to sync, ignoring priority rules
-(files from these folders will be resolved by modification time only).`,
-	Args: cobra.ExactArgs(1),
+(files from these folders will be resolved by modification time only).
+When --folders is not given, the group's (or defaults') 'folders' setting is used.
+
+Projects inherited through 'extends' are read as a lower layer: their files are
+distributed where the group's own projects have none, and they are never written to.
+
+Before anything is written, the files to distribute are scanned for credentials
+(API keys, tokens, private keys, .env-style assignments and the group's
+'secret_patterns'). The push is blocked if any are found unless --allow-secrets is given.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runPush,
 }
 
-var pushFolders string // comma-separated folder names to sync (ignoring priority)
+var (
+	pushFolders      string // comma-separated folder names to sync (ignoring priority)
+	pushAllowSecrets bool   // push even if potential secrets are found
+)
 
 func init() {
 	rootCmd.AddCommand(pushCmd)
 	pushCmd.Flags().StringVar(&pushFolders, "folders", "", "comma-separated folders to sync (ignoring priority, e.g., 'prompts,commands')")
+	pushCmd.Flags().BoolVar(&pushAllowSecrets, "allow-secrets", false, "push even if potential secrets are found")
 }
 
 func runPush(cmd *cobra.Command, args []string) error {
-	groupName := args[0]
-
 	if verbose {
 		fmt.Printf("Loading configuration...\n")
 	}
 
-	cfg, err := config.Load(cfgFile)
+	cfg, err := config.LoadWithLocal(cfgFile)
 	if err != nil {
 		return err
 	}
 
-	group, err := cfg.GetGroup(groupName)
+	var groupName string
+	if len(args) > 0 {
+		groupName = args[0]
+	} else {
+		groupName, err = cfg.DefaultGroupName()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Using default group '%s'\n", groupName)
+	}
+
+	group, err := cfg.GetEffectiveGroup(groupName)
 	if err != nil {
 		availableGroups := cfg.ListGroups()
 		return fmt.Errorf("%w\nAvailable groups: %v", err, availableGroups)
@@ -52,21 +77,34 @@ func runPush(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to parse group paths: %w", err)
 	}
 
+	opts, err := parsePushOptions(group)
+	if err != nil {
+		return err
+	}
+	strategy := opts.strategy
+
 	if dryRun {
 		fmt.Println("DRY RUN MODE - No changes will be made")
 		fmt.Println()
 	}
 
-	// Show exclude patterns if configured
+	// Show exclude/include patterns if configured
 	if len(group.Exclude) > 0 {
 		fmt.Printf("Exclude patterns: %v\n", group.Exclude)
 	}
+	if len(group.Include) > 0 {
+		fmt.Printf("Include patterns: %v\n", group.Include)
+	}
+	if len(group.RootFiles) > 0 {
+		fmt.Printf("Root files: %v\n", group.RootFiles)
+	}
 
 	// Phase 1: Collect files
 	fmt.Printf("Collecting files from group '%s'...\n", groupName)
 
-	allFiles, err := syncer.CollectFiles(projects, group.Exclude)
+	allFiles, skipped, err := syncer.CollectFilesWithReport(projects, opts.collect)
 	if err != nil {
+		printSkippedFiles(skipped)
 		return fmt.Errorf("failed to collect files: %w", err)
 	}
 
@@ -78,12 +116,17 @@ func runPush(cmd *cobra.Command, args []string) error {
 
 	for _, project := range projects {
 		count := filesByProject[project.Alias]
+		layer := ""
+		if project.Layer > 0 {
+			layer = ", inherited"
+		}
 		if count > 0 {
-			fmt.Printf("✓ %s: %d file(s) (priority: %d)\n", project.Alias, count, project.Priority)
+			fmt.Printf("✓ %s: %d file(s) (priority: %d%s)\n", project.Alias, count, project.Priority, layer)
 		} else {
-			fmt.Printf("✗ %s: no files found (priority: %d)\n", project.Alias, project.Priority)
+			fmt.Printf("✗ %s: no files found (priority: %d%s)\n", project.Alias, project.Priority, layer)
 		}
 	}
+	printSkippedFiles(skipped)
 
 	if len(allFiles) == 0 {
 		fmt.Println("\nNo files to sync")
@@ -93,27 +136,33 @@ func runPush(cmd *cobra.Command, args []string) error {
 	// Phase 2: Resolve conflicts
 	fmt.Println("\nResolving conflicts...")
 
-	// Parse folder filter
-	var folderFilter []string
+	// Parse folder filter (flag takes precedence over configured folders)
+	folderFilter := group.Folders
 	if pushFolders != "" {
 		folderFilter = strings.Split(pushFolders, ",")
 		for i := range folderFilter {
 			folderFilter[i] = strings.TrimSpace(folderFilter[i])
 		}
+	}
+	if len(folderFilter) > 0 {
 		fmt.Printf("(Folder-based priority override: %v)\n", folderFilter)
 	}
+	if strategy != syncer.StrategyNewest {
+		fmt.Printf("(Resolution strategy: %s)\n", strategy)
+	}
 
-	resolved, conflicts, err := syncer.ResolveConflicts(allFiles, folderFilter)
+	resolved, conflicts, err := syncer.ResolveConflictsWithOptions(allFiles, syncer.ResolveOptions{
+		Strategy: strategy,
+		Folders:  folderFilter,
+		Rules:    opts.rules,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to resolve conflicts: %w", err)
 	}
 
 	if len(conflicts) > 0 {
 		for _, conflict := range conflicts {
-			fmt.Printf("- %s: using %s (priority: %d)\n",
-				conflict.RelPath,
-				conflict.Resolved.Project,
-				conflict.Resolved.Priority)
+			fmt.Print(syncer.FormatConflict(conflict))
 		}
 	} else {
 		fmt.Println("No conflicts detected")
@@ -123,10 +172,21 @@ func runPush(cmd *cobra.Command, args []string) error {
 		fmt.Printf("\nTotal files to sync: %d\n", len(resolved))
 	}
 
+	// Never fan out credentials to every project unless explicitly allowed
+	if err := checkSecrets(resolved, opts.secretRules); err != nil {
+		return err
+	}
+
 	// Phase 3: Sync files
 	fmt.Println("\nSyncing...")
 
-	results, err := syncer.SyncFiles(resolved, projects, dryRun, verbose, force)
+	results, err := syncer.SyncFilesWithOptions(resolved, projects, syncer.SyncOptions{
+		DryRun:  dryRun,
+		Verbose: verbose,
+		Force:   force,
+		Vars:    group.Vars,
+		Overlay: opts.collect.Overlay,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to sync files: %w", err)
 	}
@@ -145,3 +205,96 @@ func runPush(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// pushOptions are the settings of a group parsed for collecting, resolving and syncing
+type pushOptions struct {
+	strategy    syncer.Strategy
+	rules       []syncer.Rule
+	secretRules []syncer.SecretRule
+	collect     syncer.CollectOptions
+}
+
+// parsePushOptions parses and validates the push settings of an effective group
+func parsePushOptions(group *config.Group) (pushOptions, error) {
+	var opts pushOptions
+	var err error
+
+	if opts.strategy, err = syncer.ParseStrategy(group.Strategy); err != nil {
+		return opts, err
+	}
+	if opts.rules, err = buildRules(group.Rules); err != nil {
+		return opts, err
+	}
+	if opts.secretRules, err = syncer.CompileSecretPatterns(group.SecretPatterns); err != nil {
+		return opts, err
+	}
+
+	opts.collect = syncer.CollectOptions{
+		Exclude:   group.Exclude,
+		Include:   group.Include,
+		RootFiles: group.RootFiles,
+		Dir:       group.ProjectDir(),
+	}
+	if opts.collect.Overlay, err = syncer.NormalizeOverlayDir(group.Overlay); err != nil {
+		return opts, err
+	}
+	if group.MaxFileSize != "" {
+		if opts.collect.MaxFileSize, err = utils.ParseSize(group.MaxFileSize); err != nil {
+			return opts, fmt.Errorf("invalid max_file_size: %w", err)
+		}
+	}
+	if opts.collect.Binary, err = syncer.ParseBinaryPolicy(group.Binary); err != nil {
+		return opts, err
+	}
+
+	return opts, nil
+}
+
+// buildRules converts configured rules to syncer rules, validating each strategy
+func buildRules(configRules []config.Rule) ([]syncer.Rule, error) {
+	rules := make([]syncer.Rule, 0, len(configRules))
+	for _, rule := range configRules {
+		strategy, err := syncer.ParseStrategy(rule.Strategy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule for '%s': %w", rule.Pattern, err)
+		}
+		rules = append(rules, syncer.Rule{Pattern: rule.Pattern, Strategy: strategy})
+	}
+	return rules, nil
+}
+
+// printSkippedFiles lists files left out by the size limit or binary policy
+func printSkippedFiles(skipped []syncer.SkippedFile) {
+	if len(skipped) == 0 {
+		return
+	}
+
+	fmt.Printf("Skipped %d file(s):\n", len(skipped))
+	for _, file := range skipped {
+		fmt.Printf("  - %s (from %s, %s): %s\n", syncer.DisplayPath(file.RelPath), file.Project, utils.FormatSize(file.Size), file.Reason)
+	}
+}
+
+// checkSecrets scans the files to distribute and blocks the push if potential secrets are found,
+// unless --allow-secrets is set
+func checkSecrets(resolved []syncer.ResolvedFile, rules []syncer.SecretRule) error {
+	findings, err := syncer.ScanSecrets(resolved, rules)
+	if err != nil {
+		return err
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	fmt.Printf("\n⚠️  Potential secrets found in %d place(s):\n", len(findings))
+	for _, finding := range findings {
+		fmt.Print("  " + syncer.FormatSecretFinding(finding))
+	}
+
+	if !pushAllowSecrets {
+		return fmt.Errorf("push blocked: potential secrets found (remove them, exclude the files, or use --allow-secrets)")
+	}
+
+	fmt.Println("(--allow-secrets given, continuing)")
+	return nil
+}
@@ -14,6 +14,8 @@ var rmCmd = &cobra.Command{
 	Use:   "rm <group> <path>",
 	Short: "Remove a file or directory from all projects in a group",
 	Long: `Delete the specified file or directory from all projects in the group.
+The path starts with the group's synced directory (e.g., .claude/commands/foo.md).
+Projects inherited from extended groups are not changed.
 Prompts for confirmation unless --force is specified.`,
 	Args: cobra.ExactArgs(2),
 	RunE: runRm,
@@ -33,20 +35,8 @@ func runRm(cmd *cobra.Command, args []string) error {
 	groupName := args[0]
 	rawPath := args[1]
 
-	// Validate and normalize the path
-	targetPath, err := utils.ValidateAndNormalizePath(rawPath)
-	if err != nil {
-		return fmt.Errorf("invalid path: %w", err)
-	}
-
-	// Prevent deletion of bk directory (backup directory)
-	if targetPath == "bk" || filepath.Clean(targetPath) == "bk" {
-		return fmt.Errorf("cannot delete 'bk' directory: it is reserved for backups")
-	}
-
 	if verbose {
 		fmt.Printf("Loading configuration...\n")
-		fmt.Printf("Normalized path: %s\n", targetPath)
 	}
 
 	cfg, err := config.Load(cfgFile)
@@ -54,12 +44,27 @@ func runRm(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	group, err := cfg.GetGroup(groupName)
+	group, err := cfg.GetEffectiveGroup(groupName)
 	if err != nil {
 		availableGroups := cfg.ListGroups()
 		return fmt.Errorf("%w\nAvailable groups: %v", err, availableGroups)
 	}
 
+	// Validate and normalize the path
+	targetPath, err := utils.ValidateAndNormalizePath(rawPath, group.ProjectDir())
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	// Prevent deletion of bk directory (backup directory)
+	if targetPath == "bk" || filepath.Clean(targetPath) == "bk" {
+		return fmt.Errorf("cannot delete 'bk' directory: it is reserved for backups")
+	}
+
+	if verbose {
+		fmt.Printf("Normalized path: %s\n", targetPath)
+	}
+
 	projects, err := group.GetProjectPaths()
 	if err != nil {
 		return fmt.Errorf("failed to parse group paths: %w", err)
@@ -72,6 +77,9 @@ func runRm(cmd *cobra.Command, args []string) error {
 	// Search for files in all projects
 	var targets []deleteTarget
 	for _, project := range projects {
+		if project.Layer > 0 {
+			continue // Inherited projects are not written to
+		}
 		fullPath := filepath.Join(project.Path, targetPath)
 		exists := utils.FileExists(fullPath)
 
@@ -186,6 +186,56 @@ func TestRunRm(t *testing.T) {
 	}
 }
 
+func TestRunRm_GroupDir(t *testing.T) {
+	origCfgFile, origForce := cfgFile, force
+	defer func() { cfgFile, force = origCfgFile, origForce }()
+
+	tmpDir := t.TempDir()
+	own := filepath.Join(tmpDir, "own")
+	inherited := filepath.Join(tmpDir, "inherited")
+	for _, project := range []string{own, inherited} {
+		if err := os.MkdirAll(filepath.Join(project, ".cursor", "rules"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(project, ".cursor", "rules", "a.mdc"), []byte("a"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// dir comes from defaults; the base group's project is inherited
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `defaults:
+  dir: .cursor
+groups:
+  base:
+    paths:
+      inherited: ` + inherited + `
+  app:
+    extends: [base]
+    paths:
+      own: ` + own + `
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfgFile = configPath
+	force = true
+
+	if err := runRm(nil, []string{"app", ".claude/rules/a.mdc"}); err == nil {
+		t.Error("Expected a .claude path to be rejected for a group syncing .cursor")
+	}
+
+	if err := runRm(nil, []string{"app", ".cursor/rules/a.mdc"}); err != nil {
+		t.Fatalf("runRm() failed: %v", err)
+	}
+	if utils.FileExists(filepath.Join(own, ".cursor", "rules", "a.mdc")) {
+		t.Error("File should have been deleted from the group's own project")
+	}
+	if !utils.FileExists(filepath.Join(inherited, ".cursor", "rules", "a.mdc")) {
+		t.Error("File should be kept in the inherited project")
+	}
+}
+
 func TestDeleteTarget(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/yugo-ibuki/dot-claude-sync/utils"
+)
+
+// listAliases derives the aliases of list-format projects from their normalized paths.
+// Each project is named after its directory; projects sharing a directory name (e.g.,
+// worktrees of the same repository in different parents) are told apart by their git
+// branch, or else by their parent directory. Aliases that still collide are an error,
+// since priority entries and mappings could not tell the projects apart.
+func listAliases(paths []string, dir string) ([]string, error) {
+	aliases := make([]string, len(paths))
+	count := make(map[string]int, len(paths))
+	for i, path := range paths {
+		aliases[i] = filepath.Base(projectRoot(path, dir))
+		count[aliases[i]]++
+	}
+
+	qualify(aliases, count, func(i int) string {
+		return strings.ReplaceAll(utils.GitBranch(projectRoot(paths[i], dir)), "/", "-")
+	}, true)
+	qualify(aliases, count, func(i int) string {
+		return filepath.Base(filepath.Dir(projectRoot(paths[i], dir)))
+	}, false)
+
+	owners := make(map[string]string, len(paths))
+	for i, alias := range aliases {
+		if other, exists := owners[alias]; exists {
+			return nil, fmt.Errorf("projects '%s' and '%s' both derive alias '%s'; use the map format to name them", other, paths[i], alias)
+		}
+		owners[alias] = paths[i]
+	}
+
+	return aliases, nil
+}
+
+// qualify adds a qualifier to each alias that is used more than once, as a suffix or a
+// prefix, and updates count
+func qualify(aliases []string, count map[string]int, qualifier func(int) string, suffix bool) {
+	var duplicates []int
+	for i, alias := range aliases {
+		if count[alias] > 1 {
+			duplicates = append(duplicates, i)
+		}
+	}
+
+	for _, i := range duplicates {
+		q := qualifier(i)
+		if q == "" || q == "." || q == string(filepath.Separator) {
+			continue
+		}
+		count[aliases[i]]--
+		if suffix {
+			aliases[i] += "-" + q
+		} else {
+			aliases[i] = q + "-" + aliases[i]
+		}
+		count[aliases[i]]++
+	}
+}
+
+// UniqueAlias returns alias, or alias with a numeric suffix if it is already used
+func UniqueAlias(alias string, used map[string]bool) string {
+	if !used[alias] {
+		return alias
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", alias, i)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
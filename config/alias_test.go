@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// initRepo creates a git repository at dir with one commit on branch
+func initRepo(t *testing.T, dir, branch string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", branch},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("git unavailable: %v: %s", err, output)
+		}
+	}
+}
+
+func TestListAliases(t *testing.T) {
+	t.Run("directory names", func(t *testing.T) {
+		aliases, err := listAliases([]string{"/path/web/.claude", "/path/api/.claude"}, DefaultDir)
+		if err != nil {
+			t.Fatalf("listAliases failed: %v", err)
+		}
+		if aliases[0] != "web" || aliases[1] != "api" {
+			t.Errorf("Unexpected aliases: %v", aliases)
+		}
+	})
+
+	t.Run("same directory name on different branches", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		main := filepath.Join(tmpDir, "main", "app")
+		feature := filepath.Join(tmpDir, "feature", "app")
+		initRepo(t, main, "main")
+		initRepo(t, feature, "feature/login")
+
+		aliases, err := listAliases([]string{filepath.Join(main, ".claude"), filepath.Join(feature, ".claude")}, DefaultDir)
+		if err != nil {
+			t.Fatalf("listAliases failed: %v", err)
+		}
+		if aliases[0] != "app-main" || aliases[1] != "app-feature-login" {
+			t.Errorf("Unexpected aliases: %v", aliases)
+		}
+	})
+
+	t.Run("same directory name without git", func(t *testing.T) {
+		aliases, err := listAliases([]string{"/path/one/.claude", "/other/one/.claude", "/path/two/.claude"}, DefaultDir)
+		if err != nil {
+			t.Fatalf("listAliases failed: %v", err)
+		}
+		if aliases[0] != "path-one" || aliases[1] != "other-one" || aliases[2] != "two" {
+			t.Errorf("Unexpected aliases: %v", aliases)
+		}
+	})
+
+	t.Run("colliding aliases", func(t *testing.T) {
+		_, err := listAliases([]string{"/path/one/.claude", "/path/one/.claude"}, DefaultDir)
+		if err == nil || !strings.Contains(err.Error(), "both derive alias") {
+			t.Errorf("Expected alias collision error, got %v", err)
+		}
+	})
+}
@@ -1,48 +1,266 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/yugo-ibuki/dot-claude-sync/utils"
 )
 
+// DefaultDir is the directory synced in each project when a group does not set dir
+const DefaultDir = ".claude"
+
+// CurrentVersion is the config format version written by config migrate
+const CurrentVersion = 2
+
+// BuiltinExcludes are machine-local or runtime files written by Claude that are never
+// synced unless a group disables them with builtin_excludes: false or re-includes a
+// path with a "!" pattern
+var BuiltinExcludes = []string{
+	"settings.local.json",
+	"CLAUDE.local.md",
+	".credentials.json",
+	"history.jsonl",
+	"/todos/",
+	"/shell-snapshots/",
+	"/statsig/",
+	"/ide/",
+	"/debug/",
+	"/file-history/",
+	"/session-env/",
+}
+
 // Config represents the root configuration structure
 type Config struct {
-	Groups map[string]*Group `yaml:"groups"`
+	Version      int               `yaml:"version,omitempty"`       // Config format version; 0 or 1 is the original format
+	Include      []string          `yaml:"include,omitempty"`       // Optional globs of files whose groups are merged in
+	DefaultGroup string            `yaml:"default_group,omitempty"` // Optional group used by push when none is given
+	Defaults     *Defaults         `yaml:"defaults,omitempty"`      // Optional settings inherited by every group
+	Groups       map[string]*Group `yaml:"groups"`
+
+	path         string                   // Main config file, empty when only a repository-local config was found
+	doc          *document                // Main config file as parsed, for comment-preserving saves
+	snapshot     []byte                   // Main config as last read or written, used to detect changes
+	origins      map[string]string        // File each loaded group was defined in
+	included     map[string]*includedFile // Included files, by path
+	local        string                   // Repository-local config merged in by LoadWithLocal, if any
+	localDefault string                   // Default group of the repository-local config
+}
+
+// Defaults represents settings shared by all groups.
+// Each group inherits these values and can override them.
+type Defaults struct {
+	Dir string `yaml:"dir,omitempty"` // Synced directory used when a group has none
+
+	// Which files are collected
+	Exclude         []string `yaml:"exclude,omitempty"`          // Exclude patterns prepended to every group's patterns
+	Include         []string `yaml:"include,omitempty"`          // Include patterns used when a group has none
+	BuiltinExcludes *bool    `yaml:"builtin_excludes,omitempty"` // Apply BuiltinExcludes (default: true)
+	RootFiles       []string `yaml:"root_files,omitempty"`       // Project-root files synced when a group has none
+	MaxFileSize     string   `yaml:"max_file_size,omitempty"`    // Size limit used when a group has none (e.g., "10MB")
+	Binary          string   `yaml:"binary,omitempty"`           // Binary file policy used when a group has none
+
+	// How conflicts are resolved
+	Strategy string   `yaml:"strategy,omitempty"` // Conflict resolution strategy used when a group has none
+	Rules    []Rule   `yaml:"rules,omitempty"`    // Per-path strategies prepended to every group's rules
+	Folders  []string `yaml:"folders,omitempty"`  // Default folders for push --folders
+
+	// How files are written
+	Vars           map[string]string `yaml:"vars,omitempty"`            // Template variables inherited by every group
+	Overlay        string            `yaml:"overlay,omitempty"`         // Project-local overlay directory used when a group has none
+	SecretPatterns []string          `yaml:"secret_patterns,omitempty"` // Regexes prepended to every group's secret patterns
+	Backup         *BackupConfig     `yaml:"backup,omitempty"`          // Backup settings used when a group has none
 }
 
 // Group represents a project group configuration
 type Group struct {
-	Paths    interface{} `yaml:"paths"`    // Can be map[string]string or []string
-	Priority []string    `yaml:"priority"` // Optional priority list
-	Exclude  []string    `yaml:"exclude"`  // Optional exclude patterns (glob format)
+	// Projects
+	Projects []Project           `yaml:"projects,omitempty"` // Project list (version 2)
+	Paths    interface{}         `yaml:"paths,omitempty"`    // Can be map[string]string or []string (version 1)
+	Priority []string            `yaml:"priority,omitempty"` // Optional priority list (version 1)
+	Mappings map[string][]string `yaml:"mappings,omitempty"` // Optional per-project path mappings keyed by alias (version 1)
+	Extends  []string            `yaml:"extends,omitempty"`  // Optional groups whose projects and settings this group inherits
+	Dir      string              `yaml:"dir,omitempty"`      // Optional synced directory relative to the project root (default: .claude)
+
+	// Which files are collected
+	Exclude         []string `yaml:"exclude"`                    // Optional exclude patterns (gitignore format)
+	Include         []string `yaml:"include,omitempty"`          // Optional include patterns (gitignore format)
+	BuiltinExcludes *bool    `yaml:"builtin_excludes,omitempty"` // Optional override for applying BuiltinExcludes
+	RootFiles       []string `yaml:"root_files,omitempty"`       // Optional files relative to the project root (e.g., CLAUDE.md, .mcp.json)
+	MaxFileSize     string   `yaml:"max_file_size,omitempty"`    // Optional size limit; larger files are skipped (e.g., "10MB")
+	Binary          string   `yaml:"binary,omitempty"`           // Optional binary file policy: sync (default), skip or warn
+
+	// How conflicts are resolved
+	Strategy string   `yaml:"strategy,omitempty"` // Optional conflict resolution strategy
+	Rules    []Rule   `yaml:"rules,omitempty"`    // Optional per-path resolution strategies
+	Folders  []string `yaml:"folders,omitempty"`  // Optional default folders for push --folders
+
+	// How files are written
+	Vars           map[string]string `yaml:"vars,omitempty"`            // Optional template variables (override defaults by name)
+	Overlay        string            `yaml:"overlay,omitempty"`         // Optional project-local overlay directory relative to .claude (e.g., local); unset means none
+	SecretPatterns []string          `yaml:"secret_patterns,omitempty"` // Optional regexes reported as secrets in addition to the built-in rules
+	Backup         *BackupConfig     `yaml:"backup,omitempty"`          // Optional backup settings
+
+	bases      []*Group // Extended groups with their own inheritance resolved, in extends order
+	baseDir    string   // Directory relative project paths are resolved against; empty uses the working directory
+	aliasOrder []string // Declaration order of the aliases of map-format paths
+}
+
+// UnmarshalYAML decodes a group, recording the order in which map-format paths are declared
+func (g *Group) UnmarshalYAML(node *yaml.Node) error {
+	type plain Group
+	if err := node.Decode((*plain)(g)); err != nil {
+		return err
+	}
+
+	g.aliasOrder = nil
+	if paths := mappingValue(node, "paths"); paths != nil && paths.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(paths.Content); i += 2 {
+			g.aliasOrder = append(g.aliasOrder, paths.Content[i].Value)
+		}
+	}
+	return nil
+}
+
+// MarshalYAML encodes a group, writing map-format paths in declaration order
+func (g *Group) MarshalYAML() (interface{}, error) {
+	type plain Group
+	out := plain(*g)
+
+	if paths, ok := g.Paths.(map[string]interface{}); ok {
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for _, alias := range g.PathAliases() {
+			var value yaml.Node
+			if err := value.Encode(paths[alias]); err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: alias}, &value)
+		}
+		out.Paths = node
+	}
+
+	return &out, nil
+}
+
+// PathAliases returns the aliases of map-format paths in declaration order. Aliases added
+// in code follow in the order they were added, and any others in alphabetical order.
+func (g *Group) PathAliases() []string {
+	paths, ok := g.Paths.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	aliases := make([]string, 0, len(paths))
+	seen := make(map[string]bool, len(paths))
+	for _, alias := range g.aliasOrder {
+		if _, exists := paths[alias]; exists && !seen[alias] {
+			aliases = append(aliases, alias)
+			seen[alias] = true
+		}
+	}
+
+	var rest []string
+	for alias := range paths {
+		if !seen[alias] {
+			rest = append(rest, alias)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(aliases, rest...)
+}
+
+// Project is a project of a version 2 group
+type Project struct {
+	Alias    string   `yaml:"alias"`              // Name used in output and by other settings
+	Path     string   `yaml:"path"`               // Project root or synced directory
+	Priority int      `yaml:"priority,omitempty"` // Optional priority (1 is highest); unset ranks after the rest
+	Mappings []string `yaml:"mappings,omitempty"` // Optional path mappings ("local -> canonical")
+}
+
+// Rule assigns a conflict resolution strategy to files matching a pattern
+type Rule struct {
+	Pattern  string `yaml:"pattern"`  // Pattern matched against paths relative to .claude (gitignore format)
+	Strategy string `yaml:"strategy"` // Resolution strategy for matching files
+}
+
+// BackupConfig represents backup settings
+type BackupConfig struct {
+	Keep int `yaml:"keep,omitempty"` // Number of backups to keep per project (0 keeps all)
 }
 
 // ProjectPath represents a resolved project path with alias and priority
 type ProjectPath struct {
 	Alias    string
-	Path     string
+	Path     string // Synced directory (e.g., /path/to/project/.claude)
 	Priority int
+	Root     string   // Project root containing the synced directory; empty means the parent of Path
+	Mappings []string // Path mappings from this project's layout to the canonical layout ("local -> canonical")
+	Layer    int      // 0 for the group's own projects; projects inherited through extends are 1 or more
 }
 
 // Load loads the configuration file from the specified path or default location
 func Load(configPath string) (*Config, error) {
-	path, err := getConfigPath(configPath)
-	if err != nil {
-		return nil, err
-	}
+	return load(configPath, false)
+}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+// LoadWithLocal loads the configuration like Load, merged with the groups of the
+// repository-local config found from the working directory. Without --config, a
+// repository-local config alone is enough. The result is read-only: Save refuses to write it.
+func LoadWithLocal(configPath string) (*Config, error) {
+	return load(configPath, true)
+}
+
+// load loads the configuration, merging the repository-local config if withLocal is set
+func load(configPath string, withLocal bool) (*Config, error) {
+	var localPath string
+	var hasLocal bool
+	if withLocal {
+		localPath, hasLocal = findLocalConfigFromCwd()
 	}
 
 	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	path, err := getConfigPath(configPath)
+	switch {
+	case err == nil:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+
+		config.path = path
+		if config.doc, err = parseDocument(data); err != nil {
+			return nil, err
+		}
+		if config.snapshot, err = yaml.Marshal(&config); err != nil {
+			return nil, fmt.Errorf("failed to marshal config: %w", err)
+		}
+		if config.Version > CurrentVersion {
+			return nil, fmt.Errorf("unsupported config version %d (this version of dot-claude-sync reads up to %d)", config.Version, CurrentVersion)
+		}
+		if err := config.loadIncludes(path); err != nil {
+			return nil, err
+		}
+	case configPath == "" && hasLocal:
+		// Only the repository's groups are available
+	default:
+		return nil, err
+	}
+
+	if hasLocal {
+		if err := config.loadLocal(localPath); err != nil {
+			return nil, err
+		}
 	}
 
 	return &config, nil
@@ -81,47 +299,334 @@ func (c *Config) GetGroup(name string) (*Group, error) {
 	return group, nil
 }
 
-// ListGroups returns all group names
+// GetEffectiveGroup returns the specified group with extended groups, built-in excludes and
+// defaults applied. Exclude patterns are the built-in excludes, then the defaults, then the
+// group's own, so a group can re-include any of them with a "!" pattern. Rules and secret
+// patterns are the defaults followed by the group's, and vars are merged with the group's
+// values taking precedence. Every other setting is taken from the defaults unless the group
+// sets it.
+func (c *Config) GetEffectiveGroup(name string) (*Group, error) {
+	group, err := c.resolveExtends(name, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := *group
+	d := c.Defaults
+	if d == nil {
+		d = &Defaults{}
+	}
+
+	if effective.BuiltinExcludes == nil {
+		effective.BuiltinExcludes = d.BuiltinExcludes
+	}
+	if effective.Dir == "" {
+		effective.Dir = d.Dir
+	}
+
+	var exclude []string
+	if effective.UsesBuiltinExcludes() {
+		exclude = append(exclude, BuiltinExcludes...)
+	}
+	exclude = append(exclude, d.Exclude...)
+	effective.Exclude = append(exclude, group.Exclude...)
+
+	effective.Rules = append(append([]Rule{}, d.Rules...), group.Rules...)
+	effective.SecretPatterns = append(append([]string{}, d.SecretPatterns...), group.SecretPatterns...)
+	if len(d.Vars) > 0 {
+		vars := make(map[string]string, len(d.Vars)+len(group.Vars))
+		for name, value := range d.Vars {
+			vars[name] = value
+		}
+		for name, value := range group.Vars {
+			vars[name] = value
+		}
+		effective.Vars = vars
+	}
+	if len(effective.Include) == 0 {
+		effective.Include = d.Include
+	}
+	if effective.Strategy == "" {
+		effective.Strategy = d.Strategy
+	}
+	if effective.Backup == nil {
+		effective.Backup = d.Backup
+	}
+	if len(effective.Folders) == 0 {
+		effective.Folders = d.Folders
+	}
+	if effective.Overlay == "" {
+		effective.Overlay = d.Overlay
+	}
+	if effective.MaxFileSize == "" {
+		effective.MaxFileSize = d.MaxFileSize
+	}
+	if effective.Binary == "" {
+		effective.Binary = d.Binary
+	}
+	if len(effective.RootFiles) == 0 {
+		effective.RootFiles = d.RootFiles
+	}
+
+	return &effective, nil
+}
+
+// resolveExtends returns the named group with the settings of the groups it extends folded in.
+// Inherited exclude patterns, rules and secret patterns come before the group's own, vars are
+// merged with the group's values taking precedence, and other settings are inherited when the
+// group does not set them. Earlier entries in extends take precedence over later ones.
+// chain holds the groups currently being resolved and is used to detect cycles.
+func (c *Config) resolveExtends(name string, chain []string) (*Group, error) {
+	for _, visited := range chain {
+		if visited == name {
+			return nil, fmt.Errorf("group inheritance cycle: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+	}
+
+	group, err := c.GetGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(group.Extends) == 0 {
+		return group, nil
+	}
+
+	effective := *group
+	effective.bases = nil
+	chain = append(chain, name)
+
+	var exclude, secretPatterns []string
+	var rules []Rule
+	vars := make(map[string]string, len(group.Vars))
+	for varName, value := range group.Vars {
+		vars[varName] = value
+	}
+
+	for _, baseName := range group.Extends {
+		if _, ok := c.Groups[baseName]; !ok {
+			return nil, fmt.Errorf("group '%s' extends unknown group '%s'", name, baseName)
+		}
+		base, err := c.resolveExtends(baseName, chain)
+		if err != nil {
+			return nil, err
+		}
+		if base.Dir != "" && effective.Dir != "" && filepath.Clean(base.Dir) != filepath.Clean(effective.Dir) {
+			return nil, fmt.Errorf("group '%s' extends '%s', which syncs a different dir (%s)", name, baseName, base.Dir)
+		}
+
+		exclude = append(exclude, base.Exclude...)
+		rules = append(rules, base.Rules...)
+		secretPatterns = append(secretPatterns, base.SecretPatterns...)
+		for varName, value := range base.Vars {
+			if _, ok := vars[varName]; !ok {
+				vars[varName] = value
+			}
+		}
+
+		if len(effective.Include) == 0 {
+			effective.Include = base.Include
+		}
+		if effective.Strategy == "" {
+			effective.Strategy = base.Strategy
+		}
+		if effective.Backup == nil {
+			effective.Backup = base.Backup
+		}
+		if len(effective.Folders) == 0 {
+			effective.Folders = base.Folders
+		}
+		if effective.Overlay == "" {
+			effective.Overlay = base.Overlay
+		}
+		if effective.MaxFileSize == "" {
+			effective.MaxFileSize = base.MaxFileSize
+		}
+		if effective.Binary == "" {
+			effective.Binary = base.Binary
+		}
+		if len(effective.RootFiles) == 0 {
+			effective.RootFiles = base.RootFiles
+		}
+		if effective.Dir == "" {
+			effective.Dir = base.Dir
+		}
+		if effective.BuiltinExcludes == nil {
+			effective.BuiltinExcludes = base.BuiltinExcludes
+		}
+
+		effective.bases = append(effective.bases, base)
+	}
+
+	effective.Exclude = append(exclude, group.Exclude...)
+	effective.Rules = append(rules, group.Rules...)
+	effective.SecretPatterns = append(secretPatterns, group.SecretPatterns...)
+	if len(vars) > 0 {
+		effective.Vars = vars
+	}
+
+	return &effective, nil
+}
+
+// ProjectDir returns the directory synced in each project, relative to the project root
+func (g *Group) ProjectDir() string {
+	if g.Dir == "" {
+		return DefaultDir
+	}
+	return filepath.Clean(g.Dir)
+}
+
+// UsesBuiltinExcludes reports whether BuiltinExcludes apply to the group.
+// They are Claude-specific, so by default they only apply when the group syncs .claude.
+func (g *Group) UsesBuiltinExcludes() bool {
+	if g.BuiltinExcludes != nil {
+		return *g.BuiltinExcludes
+	}
+	return g.ProjectDir() == DefaultDir
+}
+
+// ListGroups returns all group names in alphabetical order
 func (c *Config) ListGroups() []string {
 	groups := make([]string, 0, len(c.Groups))
 	for name := range c.Groups {
 		groups = append(groups, name)
 	}
+	sort.Strings(groups)
 	return groups
 }
 
-// GetProjectPaths returns resolved project paths with priorities
+// GetProjectPaths returns resolved project paths with priorities.
+// Projects inherited through extends are layered after the group's own projects, with lower
+// priority and a higher Layer.
 func (g *Group) GetProjectPaths() ([]ProjectPath, error) {
+	return g.projectPaths(g.ProjectDir())
+}
+
+// projectPaths returns the group's project paths, normalized with dir
+func (g *Group) projectPaths(dir string) ([]ProjectPath, error) {
+	var projects []ProjectPath
+	var err error
+	if len(g.Projects) > 0 {
+		if g.Paths != nil || len(g.Priority) > 0 {
+			return nil, fmt.Errorf("invalid group: 'projects' cannot be combined with 'paths' or 'priority'")
+		}
+		projects, err = g.typedProjectPaths(dir)
+	} else {
+		projects, err = g.legacyProjectPaths(dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Attach per-project path mappings
+	for alias := range g.Mappings {
+		found := false
+		for i := range projects {
+			if projects[i].Alias == alias {
+				projects[i].Mappings = append(projects[i].Mappings, g.Mappings[alias]...)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("mappings defined for unknown project '%s'", alias)
+		}
+	}
+
+	return g.appendBaseProjects(projects, dir)
+}
+
+// typedProjectPaths returns the project paths of a version 2 project list.
+// Projects without a priority rank after those with one; if none has a priority,
+// list order is used.
+func (g *Group) typedProjectPaths(dir string) ([]ProjectPath, error) {
+	lowest := 0
+	aliases := make(map[string]bool, len(g.Projects))
+	for i, project := range g.Projects {
+		switch {
+		case project.Alias == "":
+			return nil, fmt.Errorf("project at index %d has no alias", i)
+		case project.Path == "":
+			return nil, fmt.Errorf("project '%s' has no path", project.Alias)
+		case aliases[project.Alias]:
+			return nil, fmt.Errorf("duplicate project alias '%s'", project.Alias)
+		}
+		aliases[project.Alias] = true
+
+		if project.Priority > lowest {
+			lowest = project.Priority
+		}
+	}
+
+	projects := make([]ProjectPath, 0, len(g.Projects))
+	for i, project := range g.Projects {
+		resolved, err := g.resolvePath(project.Path)
+		if err != nil {
+			return nil, err
+		}
+		normalizedPath := normalizeProjectPath(resolved, dir)
+
+		priority := project.Priority
+		switch {
+		case lowest == 0:
+			priority = i + 1
+		case priority == 0:
+			priority = lowest + 1 // Lowest priority
+		}
+
+		projects = append(projects, ProjectPath{
+			Alias:    project.Alias,
+			Path:     normalizedPath,
+			Priority: priority,
+			Root:     projectRoot(normalizedPath, dir),
+			Mappings: project.Mappings,
+		})
+	}
+
+	return projects, nil
+}
+
+// legacyProjectPaths returns the project paths of a version 1 group (paths and priority)
+func (g *Group) legacyProjectPaths(dir string) ([]ProjectPath, error) {
 	var projects []ProjectPath
 
 	// Parse paths (can be map or slice)
 	switch paths := g.Paths.(type) {
 	case map[string]interface{}:
-		// Alias format
-		for alias, path := range paths {
-			pathStr, ok := path.(string)
+		// Alias format, in declaration order
+		for _, alias := range g.PathAliases() {
+			pathStr, ok := paths[alias].(string)
 			if !ok {
 				return nil, fmt.Errorf("invalid path value for alias '%s'", alias)
 			}
-			normalizedPath := normalizeClaudePath(pathStr)
+			resolved, err := g.resolvePath(pathStr)
+			if err != nil {
+				return nil, err
+			}
+			normalizedPath := normalizeProjectPath(resolved, dir)
 			projects = append(projects, ProjectPath{
 				Alias: alias,
 				Path:  normalizedPath,
+				Root:  projectRoot(normalizedPath, dir),
 			})
 		}
 	case []interface{}:
 		// Simple list format
-		for i, path := range paths {
-			pathStr, ok := path.(string)
-			if !ok {
-				return nil, fmt.Errorf("invalid path value at index %d", i)
-			}
-			normalizedPath := normalizeClaudePath(pathStr)
+		normalized, err := g.listPaths(dir)
+		if err != nil {
+			return nil, err
+		}
+		aliases, err := listAliases(normalized, dir)
+		if err != nil {
+			return nil, err
+		}
+		for i, normalizedPath := range normalized {
 			projects = append(projects, ProjectPath{
-				Alias: filepath.Base(normalizedPath),
+				Alias: aliases[i],
 				Path:  normalizedPath,
+				Root:  projectRoot(normalizedPath, dir),
 			})
 		}
+	case nil:
+		// No projects of its own (e.g., a new group or one that only extends others)
 	default:
 		return nil, fmt.Errorf("invalid paths format: must be map or list")
 	}
@@ -132,6 +637,9 @@ func (g *Group) GetProjectPaths() ([]ProjectPath, error) {
 		priorityMap := make(map[string]int)
 		for i, p := range g.Priority {
 			priorityMap[p] = i + 1
+			if expanded := utils.ExpandPath(p); expanded != p {
+				priorityMap[expanded] = i + 1 // Paths are matched after expansion
+			}
 		}
 
 		for i := range projects {
@@ -153,23 +661,133 @@ func (g *Group) GetProjectPaths() ([]ProjectPath, error) {
 	return projects, nil
 }
 
-// Save saves the configuration to the specified path or default location
+// listPaths returns the normalized paths of list-format paths
+func (g *Group) listPaths(dir string) ([]string, error) {
+	paths, _ := g.Paths.([]interface{})
+	normalized := make([]string, 0, len(paths))
+	for i, path := range paths {
+		pathStr, ok := path.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid path value at index %d", i)
+		}
+		resolved, err := g.resolvePath(pathStr)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, normalizeProjectPath(resolved, dir))
+	}
+	return normalized, nil
+}
+
+// resolvePath expands ~ and environment variables in a project path and resolves a
+// relative result against the group's base directory. A variable that is unset and has no
+// default is an error, since the path would silently point somewhere else.
+func (g *Group) resolvePath(path string) (string, error) {
+	if unset := utils.UnsetVars(path); len(unset) > 0 {
+		return "", fmt.Errorf("path '%s' uses unset environment variable(s): %s", path, strings.Join(unset, ", "))
+	}
+
+	path = utils.ExpandPath(path)
+	if g.baseDir == "" || filepath.IsAbs(path) || strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+	return filepath.Join(g.baseDir, path), nil
+}
+
+// appendBaseProjects layers the projects of extended groups under the given projects.
+// A project already in the group (by path) is not repeated.
+func (g *Group) appendBaseProjects(projects []ProjectPath, dir string) ([]ProjectPath, error) {
+	lowest := 0
+	for _, project := range projects {
+		if project.Priority > lowest {
+			lowest = project.Priority
+		}
+	}
+
+	for _, base := range g.bases {
+		baseProjects, err := base.projectPaths(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, baseProject := range baseProjects {
+			duplicate := false
+			for _, project := range projects {
+				if project.Path == baseProject.Path {
+					duplicate = true
+					break
+				}
+				if project.Alias == baseProject.Alias {
+					return nil, fmt.Errorf("project alias '%s' is used by an extended group for a different path", baseProject.Alias)
+				}
+			}
+			if duplicate {
+				continue
+			}
+
+			baseProject.Layer++
+			baseProject.Priority += lowest
+			projects = append(projects, baseProject)
+		}
+
+		// The next base is layered under everything added so far
+		for _, project := range projects {
+			if project.Priority > lowest {
+				lowest = project.Priority
+			}
+		}
+	}
+
+	return projects, nil
+}
+
+// Save saves the configuration to the specified path or default location.
+// Groups loaded from included files are written back to those files. Files are only written
+// when their content changed, and edits to a loaded file keep its comments, key order and
+// blank lines.
 func (c *Config) Save(configPath string) error {
+	if c.local != "" {
+		return fmt.Errorf("configuration includes the groups of %s and cannot be saved", c.local)
+	}
+
 	path, err := getConfigPathForSave(configPath)
 	if err != nil {
 		return err
 	}
 
-	data, err := yaml.Marshal(c)
+	main := *c
+	if len(c.included) > 0 {
+		// Groups from other files are written back to those files by saveIncludes
+		main.Groups = make(map[string]*Group, len(c.Groups))
+		for name, group := range c.Groups {
+			if _, ok := c.included[c.origins[name]]; !ok {
+				main.Groups[name] = group
+			}
+		}
+	}
+
+	data, err := yaml.Marshal(&main)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
+	if c.path != "" && path == c.path {
+		if bytes.Equal(data, c.snapshot) {
+			return c.saveIncludes() // Unchanged
+		}
+		c.snapshot = data
+		if c.doc != nil {
+			if data, err = c.doc.update(&main); err != nil {
+				return err
+			}
+		}
+	}
+
 	if err := os.WriteFile(path, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
-	return nil
+	return c.saveIncludes()
 }
 
 // getConfigPathForSave returns the configuration file path for saving
@@ -198,6 +816,11 @@ func (c *Config) AddGroup(name string) error {
 		return fmt.Errorf("group '%s' already exists", name)
 	}
 
+	if c.Version >= 2 {
+		c.Groups[name] = &Group{}
+		return nil
+	}
+
 	c.Groups[name] = &Group{
 		Paths: make(map[string]interface{}),
 	}
@@ -222,6 +845,14 @@ func (c *Config) AddProject(groupName, alias, path string) error {
 		return err
 	}
 
+	if group.usesProjects(c) {
+		if group.findProject(alias) >= 0 {
+			return fmt.Errorf("project alias '%s' already exists in group '%s'", alias, groupName)
+		}
+		group.Projects = append(group.Projects, Project{Alias: alias, Path: path})
+		return nil
+	}
+
 	// Ensure paths is a map
 	pathsMap, ok := group.Paths.(map[string]interface{})
 	if !ok {
@@ -236,6 +867,7 @@ func (c *Config) AddProject(groupName, alias, path string) error {
 	}
 
 	pathsMap[alias] = path
+	group.aliasOrder = append(group.aliasOrder, alias)
 	return nil
 }
 
@@ -246,6 +878,16 @@ func (c *Config) RemoveProject(groupName, alias string) error {
 		return err
 	}
 
+	if group.usesProjects(c) {
+		i := group.findProject(alias)
+		if i < 0 {
+			return fmt.Errorf("project alias '%s' not found in group '%s'", alias, groupName)
+		}
+		group.Projects = append(group.Projects[:i], group.Projects[i+1:]...)
+		delete(group.Mappings, alias)
+		return nil
+	}
+
 	pathsMap, ok := group.Paths.(map[string]interface{})
 	if !ok {
 		return fmt.Errorf("group '%s' does not use map format for paths", groupName)
@@ -256,6 +898,13 @@ func (c *Config) RemoveProject(groupName, alias string) error {
 	}
 
 	delete(pathsMap, alias)
+	delete(group.Mappings, alias)
+	for i, a := range group.aliasOrder {
+		if a == alias {
+			group.aliasOrder = append(group.aliasOrder[:i], group.aliasOrder[i+1:]...)
+			break
+		}
+	}
 
 	// Remove from priority list if present
 	for i, p := range group.Priority {
@@ -275,6 +924,21 @@ func (c *Config) SetPriority(groupName string, aliases []string) error {
 		return err
 	}
 
+	if group.usesProjects(c) {
+		for _, alias := range aliases {
+			if group.findProject(alias) < 0 {
+				return fmt.Errorf("project alias '%s' not found in group '%s'", alias, groupName)
+			}
+		}
+		for i := range group.Projects {
+			group.Projects[i].Priority = 0
+		}
+		for i, alias := range aliases {
+			group.Projects[group.findProject(alias)].Priority = i + 1
+		}
+		return nil
+	}
+
 	// Validate that all aliases exist in the group
 	pathsMap, ok := group.Paths.(map[string]interface{})
 	if !ok {
@@ -291,14 +955,45 @@ func (c *Config) SetPriority(groupName string, aliases []string) error {
 	return nil
 }
 
+// usesProjects reports whether the group keeps its projects in a version 2 project list
+func (g *Group) usesProjects(c *Config) bool {
+	return len(g.Projects) > 0 || (g.Paths == nil && c.Version >= 2)
+}
+
+// findProject returns the index of the project with alias in the project list, or -1
+func (g *Group) findProject(alias string) int {
+	for i, project := range g.Projects {
+		if project.Alias == alias {
+			return i
+		}
+	}
+	return -1
+}
+
 // normalizeClaude ensures path ends with .claude, appending if necessary
 func normalizeClaudePath(path string) string {
+	return normalizeProjectPath(path, DefaultDir)
+}
+
+// normalizeProjectPath ensures path ends with dir, appending it if necessary
+func normalizeProjectPath(path, dir string) string {
 	cleanPath := filepath.Clean(path)
+	dir = filepath.Clean(filepath.FromSlash(dir))
 
-	// If path doesn't end with .claude, append it
-	if !strings.HasSuffix(cleanPath, ".claude") {
-		cleanPath = filepath.Join(cleanPath, ".claude")
+	// If path doesn't end with dir, append it
+	if !strings.HasSuffix(cleanPath, dir) {
+		cleanPath = filepath.Join(cleanPath, dir)
 	}
 
 	return cleanPath
 }
+
+// projectRoot returns the project root of a normalized project path, i.e. the path without dir
+func projectRoot(path, dir string) string {
+	dir = filepath.Clean(filepath.FromSlash(dir))
+	root := strings.TrimSuffix(path, dir)
+	if root == "" {
+		return "."
+	}
+	return filepath.Clean(root)
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v3"
@@ -210,6 +212,10 @@ func TestListGroups(t *testing.T) {
 			t.Errorf("Expected group %s not found", expected)
 		}
 	}
+
+	if !reflect.DeepEqual(groups, expectedGroups) {
+		t.Errorf("Groups should be sorted, got %v", groups)
+	}
 }
 
 // TestGetProjectPaths tests the GetProjectPaths method with various formats
@@ -275,6 +281,63 @@ func TestGetProjectPaths(t *testing.T) {
 		}
 	})
 
+	t.Run("map paths keep declaration order", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "config.yaml")
+		content := `groups:
+  web:
+    paths:
+      zeta: /path/to/zeta/.claude
+      alpha: /path/to/alpha/.claude
+      mid: /path/to/mid/.claude
+`
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"zeta", "alpha", "mid"}
+		for i := 0; i < 5; i++ {
+			cfg, err := Load(configPath)
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			projects, err := cfg.Groups["web"].GetProjectPaths()
+			if err != nil {
+				t.Fatalf("Failed to get project paths: %v", err)
+			}
+			for j, proj := range projects {
+				if proj.Alias != expected[j] || proj.Priority != j+1 {
+					t.Fatalf("Expected %s with priority %d, got %s with priority %d", expected[j], j+1, proj.Alias, proj.Priority)
+				}
+			}
+		}
+	})
+
+	t.Run("added map paths are saved in order", func(t *testing.T) {
+		cfg := &Config{}
+		if err := cfg.AddGroup("web"); err != nil {
+			t.Fatal(err)
+		}
+		for _, alias := range []string{"zeta", "alpha", "mid"} {
+			if err := cfg.AddProject("web", alias, "/path/to/"+alias+"/.claude"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		if err := cfg.Save(configPath); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+		loaded, err := Load(configPath)
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+
+		if aliases := loaded.Groups["web"].PathAliases(); !reflect.DeepEqual(aliases, []string{"zeta", "alpha", "mid"}) {
+			t.Errorf("Expected aliases in the order they were added, got %v", aliases)
+		}
+	})
+
 	t.Run("list paths format", func(t *testing.T) {
 		group := &Group{
 			Paths: []interface{}{
@@ -292,9 +355,9 @@ func TestGetProjectPaths(t *testing.T) {
 			t.Fatalf("Expected 2 projects, got %d", len(projects))
 		}
 
-		// Check aliases are derived from paths
-		if projects[0].Alias != ".claude" {
-			t.Errorf("Expected alias '.claude', got %s", projects[0].Alias)
+		// Check aliases are derived from the project directories
+		if projects[0].Alias != "project1" || projects[1].Alias != "project2" {
+			t.Errorf("Expected aliases 'project1' and 'project2', got %s and %s", projects[0].Alias, projects[1].Alias)
 		}
 
 		// Check priorities are sequential
@@ -773,4 +836,360 @@ func TestGetProjectPathsValidation(t *testing.T) {
 			t.Errorf("Expected 2 projects, got %d", len(projects))
 		}
 	})
+
+	t.Run("use configured dir", func(t *testing.T) {
+		group := &Group{
+			Dir: ".github/prompts",
+			Paths: map[string]interface{}{
+				"project-a": "/path/to/a",
+				"project-b": "/path/to/b/.github/prompts",
+			},
+			Priority: []string{"project-a"},
+		}
+
+		projects, err := group.GetProjectPaths()
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		expected := map[string]ProjectPath{
+			"project-a": {Alias: "project-a", Path: "/path/to/a/.github/prompts", Root: "/path/to/a", Priority: 1},
+			"project-b": {Alias: "project-b", Path: "/path/to/b/.github/prompts", Root: "/path/to/b", Priority: 2},
+		}
+		for _, project := range projects {
+			want := expected[project.Alias]
+			if project.Path != want.Path || project.Root != want.Root || project.Priority != want.Priority {
+				t.Errorf("Expected %+v, got %+v", expected[project.Alias], project)
+			}
+		}
+		if group.UsesBuiltinExcludes() {
+			t.Error("Built-in excludes should not apply to non-.claude directories by default")
+		}
+	})
+
+	t.Run("unset environment variable", func(t *testing.T) {
+		os.Unsetenv("DCS_TEST_UNSET")
+		for _, paths := range []interface{}{
+			map[string]interface{}{"app": "$DCS_TEST_UNSET/app"},
+			[]interface{}{"$DCS_TEST_UNSET/app"},
+		} {
+			group := &Group{Paths: paths}
+			if _, err := group.GetProjectPaths(); err == nil || !strings.Contains(err.Error(), "DCS_TEST_UNSET") {
+				t.Errorf("Expected an error naming the unset variable for %v, got %v", paths, err)
+			}
+		}
+	})
+
+	t.Run("expand environment variables", func(t *testing.T) {
+		t.Setenv("WORKSPACE", "/srv/work")
+		group := &Group{
+			Paths: map[string]interface{}{
+				"project-a": "$WORKSPACE/a",
+				"project-b": "${DCS_TEST_UNSET:-/opt}/b",
+			},
+			Priority: []string{"${DCS_TEST_UNSET:-/opt}/b/.claude", "project-a"},
+		}
+
+		projects, err := group.GetProjectPaths()
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		expected := map[string]ProjectPath{
+			"project-a": {Path: "/srv/work/a/.claude", Priority: 2},
+			"project-b": {Path: "/opt/b/.claude", Priority: 1},
+		}
+		for _, project := range projects {
+			want := expected[project.Alias]
+			if project.Path != want.Path || project.Priority != want.Priority {
+				t.Errorf("%s: expected %s (priority %d), got %s (priority %d)", project.Alias, want.Path, want.Priority, project.Path, project.Priority)
+			}
+		}
+	})
+
+	t.Run("attach mappings and reject unknown aliases", func(t *testing.T) {
+		group := &Group{
+			Paths: map[string]interface{}{
+				"project-a": "/path/to/a",
+			},
+			Mappings: map[string][]string{
+				"project-a": {"commands/team/** -> commands/**"},
+			},
+		}
+
+		projects, err := group.GetProjectPaths()
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(projects) != 1 || len(projects[0].Mappings) != 1 {
+			t.Fatalf("Expected mappings to be attached, got %+v", projects)
+		}
+
+		group.Mappings["unknown"] = []string{"a.md -> b.md"}
+		if _, err := group.GetProjectPaths(); err == nil {
+			t.Error("Expected error for mappings of an unknown project")
+		}
+	})
+}
+
+func TestGetEffectiveGroup(t *testing.T) {
+	configContent := `
+defaults:
+  builtin_excludes: false
+  exclude:
+    - .DS_Store
+    - "*.swp"
+  strategy: json-merge
+  folders:
+    - prompts
+  backup:
+    keep: 3
+  vars:
+    team: platform
+    lang: go
+  overlay: private
+groups:
+  inherits:
+    paths:
+      - /path/a
+  overrides:
+    paths:
+      - /path/b
+    exclude:
+      - "!.DS_Store"
+      - "*.bak"
+    include:
+      - commands/
+    strategy: newest
+    folders:
+      - commands
+    backup:
+      keep: 1
+    vars:
+      lang: rust
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(configContent), &cfg); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	t.Run("group inherits defaults", func(t *testing.T) {
+		group, err := cfg.GetEffectiveGroup("inherits")
+		if err != nil {
+			t.Fatalf("GetEffectiveGroup failed: %v", err)
+		}
+
+		if len(group.Exclude) != 2 || group.Exclude[0] != ".DS_Store" || group.Exclude[1] != "*.swp" {
+			t.Errorf("Unexpected exclude: %v", group.Exclude)
+		}
+		if group.Strategy != "json-merge" {
+			t.Errorf("Expected strategy 'json-merge', got '%s'", group.Strategy)
+		}
+		if len(group.Folders) != 1 || group.Folders[0] != "prompts" {
+			t.Errorf("Unexpected folders: %v", group.Folders)
+		}
+		if group.Backup == nil || group.Backup.Keep != 3 {
+			t.Errorf("Unexpected backup: %+v", group.Backup)
+		}
+		if group.Overlay != "private" {
+			t.Errorf("Expected overlay 'private', got '%s'", group.Overlay)
+		}
+	})
+
+	t.Run("group overrides defaults", func(t *testing.T) {
+		group, err := cfg.GetEffectiveGroup("overrides")
+		if err != nil {
+			t.Fatalf("GetEffectiveGroup failed: %v", err)
+		}
+
+		// Exclude patterns are appended so the group can negate defaults
+		expected := []string{".DS_Store", "*.swp", "!.DS_Store", "*.bak"}
+		if len(group.Exclude) != len(expected) {
+			t.Fatalf("Expected exclude %v, got %v", expected, group.Exclude)
+		}
+		for i := range expected {
+			if group.Exclude[i] != expected[i] {
+				t.Errorf("Exclude[%d]: expected %s, got %s", i, expected[i], group.Exclude[i])
+			}
+		}
+		if len(group.Include) != 1 || group.Include[0] != "commands/" {
+			t.Errorf("Unexpected include: %v", group.Include)
+		}
+		if group.Strategy != "newest" {
+			t.Errorf("Expected strategy 'newest', got '%s'", group.Strategy)
+		}
+		if len(group.Folders) != 1 || group.Folders[0] != "commands" {
+			t.Errorf("Unexpected folders: %v", group.Folders)
+		}
+		if group.Backup == nil || group.Backup.Keep != 1 {
+			t.Errorf("Unexpected backup: %+v", group.Backup)
+		}
+		// Vars are merged by name
+		if len(group.Vars) != 2 || group.Vars["team"] != "platform" || group.Vars["lang"] != "rust" {
+			t.Errorf("Unexpected vars: %v", group.Vars)
+		}
+	})
+
+	t.Run("does not modify stored group", func(t *testing.T) {
+		if _, err := cfg.GetEffectiveGroup("overrides"); err != nil {
+			t.Fatalf("GetEffectiveGroup failed: %v", err)
+		}
+		if len(cfg.Groups["overrides"].Exclude) != 2 {
+			t.Errorf("Stored group exclude should be unchanged, got %v", cfg.Groups["overrides"].Exclude)
+		}
+	})
+
+	t.Run("missing group", func(t *testing.T) {
+		if _, err := cfg.GetEffectiveGroup("missing"); err == nil {
+			t.Error("Expected error for missing group")
+		}
+	})
+
+	t.Run("no defaults", func(t *testing.T) {
+		plain := &Config{Groups: map[string]*Group{"g": {Exclude: []string{"*.bak"}}}}
+		group, err := plain.GetEffectiveGroup("g")
+		if err != nil {
+			t.Fatalf("GetEffectiveGroup failed: %v", err)
+		}
+		if len(group.Exclude) != len(BuiltinExcludes)+1 || group.Exclude[len(group.Exclude)-1] != "*.bak" {
+			t.Errorf("Unexpected exclude: %v", group.Exclude)
+		}
+	})
+}
+
+func TestGetEffectiveGroup_Extends(t *testing.T) {
+	configContent := `
+groups:
+  company:
+    paths:
+      shared: /path/company
+    exclude:
+      - "*.bak"
+    strategy: json-merge
+    vars:
+      team: company
+      org: acme
+  product:
+    extends: [company]
+    paths:
+      app: /path/app
+      web: /path/web
+    exclude:
+      - "*.tmp"
+    vars:
+      team: product
+  loop-a:
+    extends: [loop-b]
+    paths: [/path/a]
+  loop-b:
+    extends: [loop-a]
+    paths: [/path/b]
+  broken:
+    extends: [missing]
+    paths: [/path/c]
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(configContent), &cfg); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	t.Run("inherits settings", func(t *testing.T) {
+		group, err := cfg.GetEffectiveGroup("product")
+		if err != nil {
+			t.Fatalf("GetEffectiveGroup failed: %v", err)
+		}
+
+		exclude := group.Exclude[len(BuiltinExcludes):]
+		if len(exclude) != 2 || exclude[0] != "*.bak" || exclude[1] != "*.tmp" {
+			t.Errorf("Expected inherited excludes before the group's own, got %v", exclude)
+		}
+		if group.Strategy != "json-merge" {
+			t.Errorf("Expected inherited strategy 'json-merge', got '%s'", group.Strategy)
+		}
+		if group.Vars["team"] != "product" || group.Vars["org"] != "acme" {
+			t.Errorf("Unexpected vars: %v", group.Vars)
+		}
+	})
+
+	t.Run("layers inherited projects", func(t *testing.T) {
+		group, err := cfg.GetEffectiveGroup("product")
+		if err != nil {
+			t.Fatalf("GetEffectiveGroup failed: %v", err)
+		}
+		projects, err := group.GetProjectPaths()
+		if err != nil {
+			t.Fatalf("GetProjectPaths failed: %v", err)
+		}
+
+		if len(projects) != 3 {
+			t.Fatalf("Expected 3 projects, got %+v", projects)
+		}
+		for _, project := range projects {
+			switch project.Alias {
+			case "shared":
+				if project.Layer != 1 || project.Priority != 3 {
+					t.Errorf("Expected inherited project in layer 1 with priority 3, got %+v", project)
+				}
+			default:
+				if project.Layer != 0 || project.Priority > 2 {
+					t.Errorf("Expected own project in layer 0, got %+v", project)
+				}
+			}
+		}
+	})
+
+	t.Run("rejects cycles and unknown groups", func(t *testing.T) {
+		if _, err := cfg.GetEffectiveGroup("loop-a"); err == nil {
+			t.Error("Expected error for inheritance cycle")
+		}
+		if _, err := cfg.GetEffectiveGroup("broken"); err == nil {
+			t.Error("Expected error for unknown extended group")
+		}
+	})
+}
+
+func TestBuiltinExcludes(t *testing.T) {
+	enabled := true
+	disabled := false
+
+	tests := []struct {
+		name            string
+		defaultsSetting *bool
+		groupSetting    *bool
+		wantBuiltins    bool
+	}{
+		{"applied by default", nil, nil, true},
+		{"disabled in defaults", &disabled, nil, false},
+		{"disabled in group", nil, &disabled, false},
+		{"group re-enables", &disabled, &enabled, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Defaults: &Defaults{BuiltinExcludes: tt.defaultsSetting},
+				Groups: map[string]*Group{
+					"g": {Exclude: []string{"!settings.local.json"}, BuiltinExcludes: tt.groupSetting},
+				},
+			}
+
+			group, err := cfg.GetEffectiveGroup("g")
+			if err != nil {
+				t.Fatalf("GetEffectiveGroup failed: %v", err)
+			}
+
+			hasBuiltins := len(group.Exclude) > 0 && group.Exclude[0] == BuiltinExcludes[0]
+			if hasBuiltins != tt.wantBuiltins {
+				t.Errorf("Expected builtins applied = %v, got exclude %v", tt.wantBuiltins, group.Exclude)
+			}
+			if group.UsesBuiltinExcludes() != tt.wantBuiltins {
+				t.Errorf("UsesBuiltinExcludes() = %v, want %v", group.UsesBuiltinExcludes(), tt.wantBuiltins)
+			}
+
+			// Group patterns always come last so they can negate built-ins
+			if group.Exclude[len(group.Exclude)-1] != "!settings.local.json" {
+				t.Errorf("Expected group pattern last, got %v", group.Exclude)
+			}
+		})
+	}
 }
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yugo-ibuki/dot-claude-sync/utils"
+)
+
+// loadIncludes merges the groups of files matched by the include globs into c.
+// Relative globs are resolved against the directory of mainPath. Included files may only
+// define groups, and a group name may only be defined once across all files.
+func (c *Config) loadIncludes(mainPath string) error {
+	c.origins = make(map[string]string, len(c.Groups))
+	for name := range c.Groups {
+		c.origins[name] = mainPath
+	}
+
+	if len(c.Include) == 0 {
+		return nil
+	}
+
+	mainAbs, _ := filepath.Abs(mainPath)
+	for _, pattern := range c.Include {
+		files, err := includeFiles(pattern, filepath.Dir(mainPath))
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			if abs, _ := filepath.Abs(file); abs == mainAbs {
+				continue // Never include the main file itself
+			}
+			if _, ok := c.included[file]; ok {
+				continue // Matched by more than one glob
+			}
+			if _, err := c.mergeFile(file, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// includedFile is a file whose groups were merged into the configuration
+type includedFile struct {
+	data         []byte    // Groups as last read or written, used to detect changes
+	version      int       // version declared in the file, written back with its groups
+	defaultGroup string    // default_group declared in the file, written back with its groups
+	doc          *document // File as parsed, for comment-preserving saves
+}
+
+// mergeFile merges the groups defined in file into c. The file may only define groups,
+// plus default_group when allowDefaultGroup is set. A group name may only be defined once.
+func (c *Config) mergeFile(file string, allowDefaultGroup bool) (*Config, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read included config %s: %w", file, err)
+	}
+
+	var included Config
+	if err := yaml.Unmarshal(data, &included); err != nil {
+		return nil, fmt.Errorf("failed to parse included config %s: %w", file, err)
+	}
+	if included.Defaults != nil || len(included.Include) > 0 || (included.DefaultGroup != "" && !allowDefaultGroup) {
+		return nil, fmt.Errorf("included config %s: only groups can be defined in included files", file)
+	}
+	if included.Version > CurrentVersion {
+		return nil, fmt.Errorf("included config %s: unsupported config version %d", file, included.Version)
+	}
+
+	if c.Groups == nil {
+		c.Groups = make(map[string]*Group)
+	}
+	if c.origins == nil {
+		c.origins = make(map[string]string)
+	}
+	if c.included == nil {
+		c.included = make(map[string]*includedFile)
+	}
+
+	for name, group := range included.Groups {
+		if origin, ok := c.origins[name]; ok {
+			return nil, fmt.Errorf("group '%s' is defined in both %s and %s", name, origin, file)
+		}
+		c.Groups[name] = group
+		c.origins[name] = file
+	}
+
+	info := &includedFile{version: included.Version, defaultGroup: included.DefaultGroup}
+	if info.data, err = info.marshal(included.Groups); err != nil {
+		return nil, err
+	}
+	if info.doc, err = parseDocument(data); err != nil {
+		return nil, fmt.Errorf("included config %s: %w", file, err)
+	}
+	c.included[file] = info
+
+	return &included, nil
+}
+
+// includeFiles returns the files matched by an include glob, sorted.
+// A pattern without glob characters must name an existing file.
+func includeFiles(pattern, baseDir string) ([]string, error) {
+	expanded := utils.ExpandPath(pattern)
+	if !filepath.IsAbs(expanded) {
+		expanded = filepath.Join(baseDir, expanded)
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		if _, err := os.Stat(expanded); err != nil {
+			return nil, fmt.Errorf("included config not found: %s", pattern)
+		}
+		return []string{expanded}, nil
+	}
+
+	files, err := filepath.Glob(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern '%s': %w", pattern, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// saveIncludes writes groups loaded from included files back to the files they came from.
+// Files whose groups are unchanged are left untouched.
+func (c *Config) saveIncludes() error {
+	files := make([]string, 0, len(c.included))
+	for file := range c.included {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		groups := make(map[string]*Group)
+		for name, group := range c.Groups {
+			if c.origins[name] == file {
+				groups[name] = group
+			}
+		}
+
+		included := c.included[file]
+		data, err := included.marshal(groups)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(data, included.data) {
+			continue
+		}
+		included.data = data
+
+		if included.doc != nil {
+			if data, err = included.doc.update(included.config(groups)); err != nil {
+				return err
+			}
+		}
+		if err := os.WriteFile(file, data, 0600); err != nil {
+			return fmt.Errorf("failed to write included config %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// config returns the content of the included file with the given groups
+func (f *includedFile) config(groups map[string]*Group) *Config {
+	return &Config{Version: f.version, DefaultGroup: f.defaultGroup, Groups: groups}
+}
+
+// marshal marshals groups as the content of the included file
+func (f *includedFile) marshal(groups map[string]*Group) ([]byte, error) {
+	data, err := yaml.Marshal(f.config(groups))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return data, nil
+}
+
+// Origin returns the file the group was loaded from, or an empty string for groups
+// that were not loaded from a file
+func (c *Config) Origin(name string) string {
+	return c.origins[name]
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfigFile writes a config file for include tests
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadIncludes(t *testing.T) {
+	t.Run("merge groups from included files", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mainPath := filepath.Join(tmpDir, "config.yaml")
+		teamPath := filepath.Join(tmpDir, "groups.d", "team.yaml")
+		writeConfigFile(t, mainPath, `
+include:
+  - groups.d/*.yaml
+groups:
+  personal:
+    paths: [/path/personal]
+`)
+		writeConfigFile(t, teamPath, `
+groups:
+  team:
+    paths: [/path/team]
+`)
+
+		cfg, err := Load(mainPath)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+
+		if len(cfg.Groups) != 2 {
+			t.Fatalf("Expected 2 groups, got %d", len(cfg.Groups))
+		}
+		if cfg.Origin("personal") != mainPath {
+			t.Errorf("Expected personal from %s, got %s", mainPath, cfg.Origin("personal"))
+		}
+		if cfg.Origin("team") != teamPath {
+			t.Errorf("Expected team from %s, got %s", teamPath, cfg.Origin("team"))
+		}
+	})
+
+	t.Run("reject duplicate group names", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mainPath := filepath.Join(tmpDir, "config.yaml")
+		writeConfigFile(t, mainPath, `
+include:
+  - groups.d/*.yaml
+groups:
+  team:
+    paths: [/path/a]
+`)
+		writeConfigFile(t, filepath.Join(tmpDir, "groups.d", "team.yaml"), `
+groups:
+  team:
+    paths: [/path/b]
+`)
+
+		_, err := Load(mainPath)
+		if err == nil || !strings.Contains(err.Error(), "group 'team' is defined in both") {
+			t.Errorf("Expected duplicate group error, got %v", err)
+		}
+	})
+
+	t.Run("reject defaults in included files", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mainPath := filepath.Join(tmpDir, "config.yaml")
+		writeConfigFile(t, mainPath, "include: [extra.yaml]\ngroups: {}\n")
+		writeConfigFile(t, filepath.Join(tmpDir, "extra.yaml"), "defaults:\n  strategy: newest\n")
+
+		if _, err := Load(mainPath); err == nil {
+			t.Error("Expected error for defaults in an included file")
+		}
+	})
+
+	t.Run("missing literal include", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		mainPath := filepath.Join(tmpDir, "config.yaml")
+		writeConfigFile(t, mainPath, "include: [missing.yaml]\ngroups: {}\n")
+
+		if _, err := Load(mainPath); err == nil {
+			t.Error("Expected error for a missing included file")
+		}
+	})
+}
+
+func TestSaveIncludes(t *testing.T) {
+	tmpDir := t.TempDir()
+	mainPath := filepath.Join(tmpDir, "config.yaml")
+	teamPath := filepath.Join(tmpDir, "groups.d", "team.yaml")
+	otherPath := filepath.Join(tmpDir, "groups.d", "other.yaml")
+	writeConfigFile(t, mainPath, "include: [groups.d/*.yaml]\ngroups: {}\n")
+	writeConfigFile(t, teamPath, "groups:\n  team:\n    paths:\n      a: /path/a\n")
+	otherContent := "# kept as written\ngroups:\n  other:\n    paths: [/path/other]\n"
+	writeConfigFile(t, otherPath, otherContent)
+
+	cfg, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := cfg.AddProject("team", "b", "/path/b"); err != nil {
+		t.Fatalf("AddProject failed: %v", err)
+	}
+	if err := cfg.AddGroup("personal"); err != nil {
+		t.Fatalf("AddGroup failed: %v", err)
+	}
+	if err := cfg.Save(mainPath); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	reloaded, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if reloaded.Origin("team") != teamPath || reloaded.Origin("personal") != mainPath {
+		t.Errorf("Groups saved to unexpected files: team=%s personal=%s", reloaded.Origin("team"), reloaded.Origin("personal"))
+	}
+	if paths, _ := reloaded.Groups["team"].Paths.(map[string]interface{}); len(paths) != 2 {
+		t.Errorf("Expected the new project in the included file, got %v", reloaded.Groups["team"].Paths)
+	}
+
+	// Unchanged included files are not rewritten
+	data, err := os.ReadFile(otherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != otherContent {
+		t.Errorf("Unchanged included file was rewritten:\n%s", data)
+	}
+}
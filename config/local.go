@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LocalConfigName is the file name of a repository-local config
+const LocalConfigName = ".dot-claude-sync.yaml"
+
+// FindLocalConfig walks up from dir looking for a repository-local config.
+// The search stops at the root of the git repository containing dir, or at the
+// filesystem root when dir is not inside a repository.
+func FindLocalConfig(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		path := filepath.Join(dir, LocalConfigName)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+			return path, true
+		}
+
+		// .git is a directory in a repository and a file in a worktree
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return "", false
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// findLocalConfigFromCwd finds the repository-local config for the working directory
+func findLocalConfigFromCwd() (string, bool) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	return FindLocalConfig(cwd)
+}
+
+// loadLocal merges the groups of a repository-local config into c.
+// Relative project paths are resolved against the config's directory, and its
+// default_group, or its only group, becomes the default group.
+func (c *Config) loadLocal(path string) error {
+	local, err := c.mergeFile(path, true)
+	if err != nil {
+		return err
+	}
+
+	// The file belongs to the repository and is never written back
+	delete(c.included, path)
+	c.local = path
+
+	// Paths in a committed config are relative to the repository, not the working directory
+	for _, group := range local.Groups {
+		group.baseDir = filepath.Dir(path)
+	}
+
+	switch {
+	case local.DefaultGroup != "":
+		if _, ok := local.Groups[local.DefaultGroup]; !ok {
+			if _, ok := c.Groups[local.DefaultGroup]; !ok {
+				return fmt.Errorf("%s: default group '%s' not found", path, local.DefaultGroup)
+			}
+		}
+		c.localDefault = local.DefaultGroup
+	case len(local.Groups) == 1:
+		for name := range local.Groups {
+			c.localDefault = name
+		}
+	}
+
+	return nil
+}
+
+// DefaultGroupName returns the group to use when none is given: the repository-local
+// default if there is one, otherwise the configured default_group
+func (c *Config) DefaultGroupName() (string, error) {
+	switch {
+	case c.localDefault != "":
+		return c.localDefault, nil
+	case c.DefaultGroup != "":
+		if _, ok := c.Groups[c.DefaultGroup]; !ok {
+			return "", fmt.Errorf("default group '%s' not found in configuration", c.DefaultGroup)
+		}
+		return c.DefaultGroup, nil
+	default:
+		return "", fmt.Errorf("no group given and no default group configured (set default_group or add a %s to the repository)", LocalConfigName)
+	}
+}
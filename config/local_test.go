@@ -0,0 +1,156 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestFindLocalConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	nested := filepath.Join(repo, "src", "pkg")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := FindLocalConfig(nested); ok {
+		t.Error("Expected no local config before one is created")
+	}
+
+	// A config above the repository root is not used
+	writeConfigFile(t, filepath.Join(tmpDir, LocalConfigName), "groups: {}\n")
+	if _, ok := FindLocalConfig(nested); ok {
+		t.Error("Search should stop at the repository root")
+	}
+
+	localPath := filepath.Join(repo, LocalConfigName)
+	writeConfigFile(t, localPath, "groups: {}\n")
+	path, ok := FindLocalConfig(nested)
+	if !ok || path != localPath {
+		t.Errorf("Expected %s, got %s (found: %v)", localPath, path, ok)
+	}
+}
+
+func TestLoadLocal(t *testing.T) {
+	tmpDir := t.TempDir()
+	repo := filepath.Join(tmpDir, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	mainPath := filepath.Join(tmpDir, "config.yaml")
+	writeConfigFile(t, mainPath, "groups:\n  personal:\n    paths: [/path/personal]\n")
+	localPath := filepath.Join(repo, LocalConfigName)
+	writeConfigFile(t, localPath, `
+groups:
+  team:
+    paths:
+      app: .
+      docs: ../docs
+`)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(repo); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	// Only LoadWithLocal merges the repository's groups
+	cfg, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, ok := cfg.Groups["team"]; ok {
+		t.Error("Load should not merge the local config")
+	}
+
+	cfg, err = LoadWithLocal(mainPath)
+	if err != nil {
+		t.Fatalf("LoadWithLocal failed: %v", err)
+	}
+
+	if len(cfg.Groups) != 2 {
+		t.Fatalf("Expected global and local groups, got %v", cfg.ListGroups())
+	}
+	if cfg.Origin("team") != localPath {
+		t.Errorf("Expected team from %s, got %s", localPath, cfg.Origin("team"))
+	}
+
+	// The only local group is the default
+	name, err := cfg.DefaultGroupName()
+	if err != nil || name != "team" {
+		t.Errorf("Expected default group 'team', got '%s' (%v)", name, err)
+	}
+
+	// Relative paths are resolved against the repository
+	projects, err := cfg.Groups["team"].GetProjectPaths()
+	if err != nil {
+		t.Fatalf("GetProjectPaths failed: %v", err)
+	}
+	expected := map[string]string{
+		"app":  filepath.Join(repo, ".claude"),
+		"docs": filepath.Join(tmpDir, "docs", ".claude"),
+	}
+	for _, project := range projects {
+		if project.Path != expected[project.Alias] {
+			t.Errorf("%s: expected %s, got %s", project.Alias, expected[project.Alias], project.Path)
+		}
+	}
+
+	// The local file is validated along with the others
+	if files := cfg.Files(); len(files) != 2 || files[1] != localPath {
+		t.Errorf("Expected the local config in %v", files)
+	}
+
+	// Neither file is written back
+	before, err := os.ReadFile(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(mainPath); err == nil {
+		t.Error("Expected Save to refuse a config with local groups")
+	}
+	after, err := os.ReadFile(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Error("Local config should not be rewritten")
+	}
+	data, err := os.ReadFile(mainPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved Config
+	if err := yaml.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saved.Groups["team"]; ok {
+		t.Error("Local group should not be saved to the global config")
+	}
+}
+
+func TestDefaultGroupName(t *testing.T) {
+	cfg := &Config{Groups: map[string]*Group{"a": {}, "b": {}}}
+	if _, err := cfg.DefaultGroupName(); err == nil {
+		t.Error("Expected error without a default group")
+	}
+
+	cfg.DefaultGroup = "b"
+	if name, err := cfg.DefaultGroupName(); err != nil || name != "b" {
+		t.Errorf("Expected default group 'b', got '%s' (%v)", name, err)
+	}
+
+	cfg.DefaultGroup = "missing"
+	if _, err := cfg.DefaultGroupName(); err == nil {
+		t.Error("Expected error for an unknown default group")
+	}
+}
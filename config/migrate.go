@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yugo-ibuki/dot-claude-sync/utils"
+)
+
+// Migrate converts every group to the version 2 project list and sets the config version.
+// Aliases of list-format paths are derived from the project directory names, the priority
+// list becomes per-project priorities and mappings move to their projects.
+// It reports whether anything changed.
+func (c *Config) Migrate() (bool, error) {
+	changed := c.Version < CurrentVersion
+	c.Version = CurrentVersion
+	if changed && c.doc != nil {
+		if err := c.doc.setFirst("version", CurrentVersion); err != nil {
+			return false, err
+		}
+	}
+
+	for _, name := range c.ListGroups() {
+		group := c.Groups[name]
+		if group.Paths == nil && len(group.Priority) == 0 {
+			continue
+		}
+		if len(group.Projects) > 0 {
+			return false, fmt.Errorf("group '%s': 'projects' cannot be combined with 'paths' or 'priority'", name)
+		}
+
+		dir := group.ProjectDir()
+		if effective, err := c.GetEffectiveGroup(name); err == nil {
+			dir = effective.ProjectDir()
+		}
+
+		projects, err := migrateProjects(group, dir)
+		if err != nil {
+			return false, fmt.Errorf("group '%s': %w", name, err)
+		}
+
+		if doc := c.document(name); doc != nil {
+			if err := doc.migrateGroup(name, projects); err != nil {
+				return false, err
+			}
+		}
+
+		group.Projects = projects
+		group.Paths = nil
+		group.Priority = nil
+		if len(group.Mappings) == 0 {
+			group.Mappings = nil
+		}
+		changed = true
+
+		if included, ok := c.included[c.origins[name]]; ok && included.version < CurrentVersion {
+			included.version = CurrentVersion
+			if included.doc != nil {
+				if err := included.doc.setFirst("version", CurrentVersion); err != nil {
+					return false, err
+				}
+			}
+		}
+	}
+
+	return changed, nil
+}
+
+// migrateProjects converts a version 1 group's paths and priority list to a project list.
+// Mappings of converted projects are moved from the group to the projects.
+func migrateProjects(group *Group, dir string) ([]Project, error) {
+	type legacyProject struct {
+		alias string // Alias in version 1 (derived from the project directory for lists)
+		path  string
+	}
+
+	var legacy []legacyProject
+	switch paths := group.Paths.(type) {
+	case map[string]interface{}:
+		for _, alias := range group.PathAliases() {
+			path, ok := paths[alias].(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid path value for alias '%s'", alias)
+			}
+			legacy = append(legacy, legacyProject{alias: alias, path: path})
+		}
+	case []interface{}:
+		normalized, err := group.listPaths(dir)
+		if err != nil {
+			return nil, err
+		}
+		aliases, err := listAliases(normalized, dir)
+		if err != nil {
+			return nil, err
+		}
+		for i, item := range paths {
+			legacy = append(legacy, legacyProject{alias: aliases[i], path: item.(string)})
+		}
+	case nil:
+	default:
+		return nil, fmt.Errorf("invalid paths format: must be map or list")
+	}
+
+	priorities := make(map[string]int, len(group.Priority))
+	for i, entry := range group.Priority {
+		priorities[entry] = i + 1
+		if expanded := utils.ExpandPath(entry); expanded != entry {
+			priorities[expanded] = i + 1 // Paths are matched after expansion, as in GetProjectPaths
+		}
+	}
+
+	projects := make([]Project, 0, len(legacy))
+	for _, old := range legacy {
+		priority, ok := priorities[old.alias]
+		if resolved, err := group.resolvePath(old.path); !ok && err == nil {
+			priority = priorities[normalizeProjectPath(resolved, dir)]
+		}
+
+		projects = append(projects, Project{
+			Alias:    old.alias,
+			Path:     old.path,
+			Priority: priority,
+			Mappings: group.Mappings[old.alias],
+		})
+	}
+
+	for _, old := range legacy {
+		delete(group.Mappings, old.alias)
+	}
+
+	// Prioritized projects first, in priority order
+	sort.SliceStable(projects, func(i, j int) bool {
+		a, b := projects[i].Priority, projects[j].Priority
+		return a != 0 && (b == 0 || a < b)
+	})
+
+	return projects, nil
+}
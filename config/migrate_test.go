@@ -0,0 +1,234 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestMigrate(t *testing.T) {
+	configContent := `
+groups:
+  web:
+    paths:
+      app: /path/app
+      admin: /path/admin
+      docs: /path/docs
+    priority: [docs, app]
+    mappings:
+      admin: ["commands/team/** -> commands/**"]
+  list:
+    paths:
+      - /path/one
+      - /other/one/.claude
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(configContent), &cfg); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	before, err := cfg.Groups["web"].GetProjectPaths()
+	if err != nil {
+		t.Fatalf("GetProjectPaths failed: %v", err)
+	}
+
+	changed, err := cfg.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if !changed || cfg.Version != CurrentVersion {
+		t.Fatalf("Expected migration to version %d, got version %d (changed: %v)", CurrentVersion, cfg.Version, changed)
+	}
+
+	web := cfg.Groups["web"]
+	if web.Paths != nil || web.Priority != nil || web.Mappings != nil {
+		t.Errorf("Expected version 1 fields to be cleared, got %+v", web)
+	}
+	expected := []Project{
+		{Alias: "docs", Path: "/path/docs", Priority: 1},
+		{Alias: "app", Path: "/path/app", Priority: 2},
+		{Alias: "admin", Path: "/path/admin", Mappings: []string{"commands/team/** -> commands/**"}},
+	}
+	if len(web.Projects) != len(expected) {
+		t.Fatalf("Expected %d projects, got %+v", len(expected), web.Projects)
+	}
+	for i, want := range expected {
+		got := web.Projects[i]
+		if got.Alias != want.Alias || got.Path != want.Path || got.Priority != want.Priority || len(got.Mappings) != len(want.Mappings) {
+			t.Errorf("Project %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	// Resolved projects are unchanged by the migration
+	after, err := web.GetProjectPaths()
+	if err != nil {
+		t.Fatalf("GetProjectPaths failed: %v", err)
+	}
+	priorities := make(map[string]int)
+	for _, project := range before {
+		priorities[project.Alias] = project.Priority
+	}
+	for _, project := range after {
+		if priorities[project.Alias] != project.Priority {
+			t.Errorf("%s: priority changed from %d to %d", project.Alias, priorities[project.Alias], project.Priority)
+		}
+	}
+
+	// List paths keep the aliases derived from their project directories
+	list := cfg.Groups["list"].Projects
+	if len(list) != 2 || list[0].Alias != "path-one" || list[1].Alias != "other-one" {
+		t.Errorf("Unexpected list aliases: %+v", list)
+	}
+
+	changed, err = cfg.Migrate()
+	if err != nil || changed {
+		t.Errorf("Expected a second migration to change nothing, got changed=%v err=%v", changed, err)
+	}
+}
+
+func TestMigrate_PriorityByPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configContent := `
+groups:
+  web:
+    paths: [~/app, ~/docs]
+    priority: [$HOME/docs/.claude, $HOME/app/.claude]
+`
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(configContent), &cfg); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	before, err := cfg.Groups["web"].GetProjectPaths()
+	if err != nil {
+		t.Fatalf("GetProjectPaths failed: %v", err)
+	}
+	if _, err := cfg.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	// Priority entries given as paths match after expansion
+	projects := cfg.Groups["web"].Projects
+	if len(projects) != 2 || projects[0].Alias != "docs" || projects[0].Priority != 1 ||
+		projects[1].Alias != "app" || projects[1].Priority != 2 {
+		t.Errorf("Unexpected projects: %+v", projects)
+	}
+	for i, project := range before {
+		if want := map[string]int{"docs": 1, "app": 2}[project.Alias]; project.Priority != want {
+			t.Errorf("Project %d: expected %s to have priority %d before migrating, got %d", i, project.Alias, want, project.Priority)
+		}
+	}
+}
+
+func TestMigrate_SaveKeepsComments(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	configContent := `# Team config
+groups:
+  web:
+    # Web projects
+    paths:
+      a: /tmp/e2e/a
+      # The backend
+      b: /tmp/e2e/b   # second
+    priority: [b]
+  list:
+    paths:
+      - /tmp/e2e/one # first
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, err := cfg.Migrate(); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if err := cfg.Save(configPath); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `# Team config
+version: 2
+groups:
+  web:
+    # Web projects
+    projects:
+      # The backend
+      - alias: b
+        path: /tmp/e2e/b # second
+        priority: 1
+      - alias: a
+        path: /tmp/e2e/a
+  list:
+    projects:
+      - alias: one
+        path: /tmp/e2e/one # first
+`
+	if string(data) != expected {
+		t.Errorf("Unexpected migrated config:\n%s\nexpected:\n%s", data, expected)
+	}
+}
+
+func TestVersion2Projects(t *testing.T) {
+	cfg := &Config{Version: 2}
+	if err := cfg.AddGroup("web"); err != nil {
+		t.Fatalf("AddGroup failed: %v", err)
+	}
+	if err := cfg.AddProject("web", "app", "/path/app"); err != nil {
+		t.Fatalf("AddProject failed: %v", err)
+	}
+	if err := cfg.AddProject("web", "admin", "/path/admin"); err != nil {
+		t.Fatalf("AddProject failed: %v", err)
+	}
+	if err := cfg.AddProject("web", "app", "/path/other"); err == nil {
+		t.Error("Expected error for duplicate alias")
+	}
+	if err := cfg.SetPriority("web", []string{"admin"}); err != nil {
+		t.Fatalf("SetPriority failed: %v", err)
+	}
+
+	projects, err := cfg.Groups["web"].GetProjectPaths()
+	if err != nil {
+		t.Fatalf("GetProjectPaths failed: %v", err)
+	}
+	for _, project := range projects {
+		want := map[string]int{"admin": 1, "app": 2}[project.Alias]
+		if project.Priority != want {
+			t.Errorf("%s: expected priority %d, got %d", project.Alias, want, project.Priority)
+		}
+		if project.Path != filepath.Join("/path", project.Alias, ".claude") {
+			t.Errorf("%s: unexpected path %s", project.Alias, project.Path)
+		}
+	}
+
+	if err := cfg.RemoveProject("web", "app"); err != nil {
+		t.Fatalf("RemoveProject failed: %v", err)
+	}
+	if len(cfg.Groups["web"].Projects) != 1 {
+		t.Errorf("Expected 1 project after removal, got %+v", cfg.Groups["web"].Projects)
+	}
+
+	cfg.Groups["web"].Projects = append(cfg.Groups["web"].Projects, Project{Alias: "admin", Path: "/path/x"})
+	if _, err := cfg.Groups["web"].GetProjectPaths(); err == nil {
+		t.Error("Expected error for duplicate alias in project list")
+	}
+}
+
+func TestLoadUnsupportedVersion(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfigFile(t, configPath, "version: 3\ngroups: {}\n")
+
+	if _, err := Load(configPath); err == nil {
+		t.Error("Expected error for unsupported version")
+	}
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// RenameGroup renames a group, updating the groups that extend it and any default group
+// that names it
+func (c *Config) RenameGroup(oldName, newName string) error {
+	group, err := c.GetGroup(oldName)
+	if err != nil {
+		return err
+	}
+	if newName == "" {
+		return fmt.Errorf("group name cannot be empty")
+	}
+	if _, exists := c.Groups[newName]; exists {
+		return fmt.Errorf("group '%s' already exists", newName)
+	}
+
+	if doc := c.document(oldName); doc != nil {
+		doc.rename([]string{"groups"}, oldName, newName)
+	}
+
+	delete(c.Groups, oldName)
+	c.Groups[newName] = group
+
+	if origin, ok := c.origins[oldName]; ok {
+		delete(c.origins, oldName)
+		c.origins[newName] = origin
+	}
+
+	for _, other := range c.Groups {
+		for i, base := range other.Extends {
+			if base == oldName {
+				other.Extends[i] = newName
+			}
+		}
+	}
+
+	if c.DefaultGroup == oldName {
+		c.DefaultGroup = newName
+	}
+	if c.localDefault == oldName {
+		c.localDefault = newName
+	}
+	for _, included := range c.included {
+		if included.defaultGroup == oldName {
+			included.defaultGroup = newName
+		}
+	}
+
+	return nil
+}
+
+// RenameProject renames a project of a group, updating its priority entry and mappings
+func (c *Config) RenameProject(groupName, oldAlias, newAlias string) error {
+	group, err := c.GetGroup(groupName)
+	if err != nil {
+		return err
+	}
+	if _, _, err := group.project(c, groupName, oldAlias); err != nil {
+		return err
+	}
+	if newAlias == "" {
+		return fmt.Errorf("project alias cannot be empty")
+	}
+	if group.hasProject(c, newAlias) {
+		return fmt.Errorf("project alias '%s' already exists in group '%s'", newAlias, groupName)
+	}
+
+	// Renamed entries keep their place in the file, which for paths is their default priority
+	if doc := c.document(groupName); doc != nil {
+		for _, key := range []string{"projects", "paths", "mappings"} {
+			doc.rename([]string{"groups", groupName, key}, oldAlias, newAlias)
+		}
+	}
+
+	if group.usesProjects(c) {
+		group.Projects[group.findProject(oldAlias)].Alias = newAlias
+	} else {
+		pathsMap := group.Paths.(map[string]interface{})
+		pathsMap[newAlias] = pathsMap[oldAlias]
+		delete(pathsMap, oldAlias)
+
+		replaced := false
+		for i, alias := range group.aliasOrder {
+			if alias == oldAlias {
+				group.aliasOrder[i] = newAlias
+				replaced = true
+			}
+		}
+		if !replaced {
+			group.aliasOrder = append(group.aliasOrder, newAlias)
+		}
+
+		for i, entry := range group.Priority {
+			if entry == oldAlias {
+				group.Priority[i] = newAlias
+			}
+		}
+	}
+
+	if mappings, ok := group.Mappings[oldAlias]; ok {
+		delete(group.Mappings, oldAlias)
+		group.Mappings[newAlias] = mappings
+	}
+
+	return nil
+}
+
+// MoveProject moves a project with its mappings from one group to another. Its priority
+// is not carried over, so it ranks after the target group's prioritized projects.
+func (c *Config) MoveProject(fromGroup, toGroup, alias string) error {
+	if fromGroup == toGroup {
+		return fmt.Errorf("project '%s' is already in group '%s'", alias, toGroup)
+	}
+
+	from, err := c.GetGroup(fromGroup)
+	if err != nil {
+		return err
+	}
+	to, err := c.GetGroup(toGroup)
+	if err != nil {
+		return err
+	}
+
+	path, mappings, err := from.project(c, fromGroup, alias)
+	if err != nil {
+		return err
+	}
+	if to.hasProject(c, alias) {
+		return fmt.Errorf("project alias '%s' already exists in group '%s'", alias, toGroup)
+	}
+	if _, isList := to.Paths.([]interface{}); isList {
+		return fmt.Errorf("group '%s' does not use map format for paths", toGroup)
+	}
+
+	// A relative path stays relative to the directory of the file it was written in
+	if from.baseDir != to.baseDir && from.baseDir != "" && !filepath.IsAbs(path) &&
+		!strings.HasPrefix(path, "~") && !strings.HasPrefix(path, "$") {
+		path = filepath.Join(from.baseDir, path)
+	}
+
+	if err := c.RemoveProject(fromGroup, alias); err != nil {
+		return err
+	}
+	if err := c.AddProject(toGroup, alias, path); err != nil {
+		return err
+	}
+
+	if len(mappings) > 0 {
+		if to.usesProjects(c) {
+			to.Projects[to.findProject(alias)].Mappings = mappings
+		} else {
+			if to.Mappings == nil {
+				to.Mappings = make(map[string][]string)
+			}
+			to.Mappings[alias] = mappings
+		}
+	}
+
+	return nil
+}
+
+// project returns the path and mappings of the project with alias. Projects of list-format
+// groups cannot be addressed, since their aliases are derived from their paths.
+func (g *Group) project(c *Config, groupName, alias string) (string, []string, error) {
+	if g.usesProjects(c) {
+		i := g.findProject(alias)
+		if i < 0 {
+			return "", nil, fmt.Errorf("project alias '%s' not found in group '%s'", alias, groupName)
+		}
+		mappings := append(append([]string{}, g.Projects[i].Mappings...), g.Mappings[alias]...)
+		return g.Projects[i].Path, mappings, nil
+	}
+
+	pathsMap, ok := g.Paths.(map[string]interface{})
+	if !ok {
+		return "", nil, fmt.Errorf("group '%s' does not use map format for paths", groupName)
+	}
+	path, exists := pathsMap[alias]
+	if !exists {
+		return "", nil, fmt.Errorf("project alias '%s' not found in group '%s'", alias, groupName)
+	}
+	pathStr, ok := path.(string)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid path value for alias '%s'", alias)
+	}
+	return pathStr, g.Mappings[alias], nil
+}
+
+// hasProject reports whether the group has its own project with alias
+func (g *Group) hasProject(c *Config, alias string) bool {
+	if g.usesProjects(c) {
+		return g.findProject(alias) >= 0
+	}
+	pathsMap, _ := g.Paths.(map[string]interface{})
+	_, exists := pathsMap[alias]
+	return exists
+}
+
+// document returns the parsed file the group is defined in, or nil if there is none
+func (c *Config) document(groupName string) *document {
+	if included, ok := c.included[c.origins[groupName]]; ok {
+		return included.doc
+	}
+	return c.doc
+}
@@ -0,0 +1,263 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenameGroup(t *testing.T) {
+	tmpDir := t.TempDir()
+	mainPath := filepath.Join(tmpDir, "config.yaml")
+	writeConfigFile(t, mainPath, `
+include: [team.yaml]
+default_group: web
+groups:
+  web:
+    paths:
+      app: /path/app
+  web-extra:
+    extends: [web]
+    paths:
+      docs: /path/docs
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "team.yaml"), `
+groups:
+  team:
+    extends: [web]
+`)
+
+	cfg, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if err := cfg.RenameGroup("web", "team"); err == nil {
+		t.Error("Expected error when renaming to an existing group")
+	}
+	if err := cfg.RenameGroup("missing", "other"); err == nil {
+		t.Error("Expected error for unknown group")
+	}
+
+	if err := cfg.RenameGroup("web", "frontend"); err != nil {
+		t.Fatalf("RenameGroup() failed: %v", err)
+	}
+	if err := cfg.Save(mainPath); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+
+	loaded, err := Load(mainPath)
+	if err != nil {
+		t.Fatalf("Load() of saved config failed: %v", err)
+	}
+	if _, exists := loaded.Groups["web"]; exists {
+		t.Error("Old group name should be gone")
+	}
+	if _, exists := loaded.Groups["frontend"]; !exists {
+		t.Fatal("Renamed group should exist")
+	}
+	if loaded.DefaultGroup != "frontend" {
+		t.Errorf("Expected default group 'frontend', got %q", loaded.DefaultGroup)
+	}
+	for _, name := range []string{"web-extra", "team"} {
+		if !reflect.DeepEqual(loaded.Groups[name].Extends, []string{"frontend"}) {
+			t.Errorf("%s: expected extends [frontend], got %v", name, loaded.Groups[name].Extends)
+		}
+	}
+	if origin := loaded.Origin("team"); origin != filepath.Join(tmpDir, "team.yaml") {
+		t.Errorf("Included group should stay in its file, got origin %s", origin)
+	}
+}
+
+func TestRenameProject(t *testing.T) {
+	t.Run("map format", func(t *testing.T) {
+		cfg := &Config{Groups: map[string]*Group{
+			"web": {
+				Paths:    map[string]interface{}{"app": "/path/app", "admin": "/path/admin"},
+				Priority: []string{"admin", "app"},
+				Mappings: map[string][]string{"app": {"a -> b"}},
+			},
+		}}
+		cfg.Groups["web"].aliasOrder = []string{"app", "admin"}
+
+		if err := cfg.RenameProject("web", "app", "admin"); err == nil {
+			t.Error("Expected error when renaming to an existing alias")
+		}
+		if err := cfg.RenameProject("web", "app", "frontend"); err != nil {
+			t.Fatalf("RenameProject() failed: %v", err)
+		}
+
+		group := cfg.Groups["web"]
+		if !reflect.DeepEqual(group.PathAliases(), []string{"frontend", "admin"}) {
+			t.Errorf("Expected alias to keep its position, got %v", group.PathAliases())
+		}
+		if !reflect.DeepEqual(group.Priority, []string{"admin", "frontend"}) {
+			t.Errorf("Expected priority to be updated, got %v", group.Priority)
+		}
+		if _, ok := group.Mappings["frontend"]; !ok || len(group.Mappings) != 1 {
+			t.Errorf("Expected mappings to be moved, got %v", group.Mappings)
+		}
+		if _, err := group.GetProjectPaths(); err != nil {
+			t.Errorf("GetProjectPaths() failed after rename: %v", err)
+		}
+	})
+
+	t.Run("version 2", func(t *testing.T) {
+		cfg := &Config{Version: 2, Groups: map[string]*Group{
+			"web": {Projects: []Project{{Alias: "app", Path: "/path/app", Priority: 1}}},
+		}}
+		if err := cfg.RenameProject("web", "app", "frontend"); err != nil {
+			t.Fatalf("RenameProject() failed: %v", err)
+		}
+		if project := cfg.Groups["web"].Projects[0]; project.Alias != "frontend" || project.Priority != 1 {
+			t.Errorf("Unexpected project after rename: %+v", project)
+		}
+	})
+
+	t.Run("list format", func(t *testing.T) {
+		cfg := &Config{Groups: map[string]*Group{
+			"web": {Paths: []interface{}{"/path/app"}},
+		}}
+		if err := cfg.RenameProject("web", "app", "frontend"); err == nil || !strings.Contains(err.Error(), "map format") {
+			t.Errorf("Expected map format error, got %v", err)
+		}
+	})
+}
+
+func TestRenameProject_KeepsPriorityOnSave(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"map format", `
+groups:
+  g:
+    paths:
+      zeta: /path/zeta
+      alpha: /path/alpha # renamed
+      mid: /path/mid
+    mappings:
+      alpha: ["a -> b"]
+`},
+		{"version 2", `
+version: 2
+groups:
+  g:
+    projects:
+      - alias: zeta
+        path: /path/zeta
+      - alias: alpha # renamed
+        path: /path/alpha
+      - alias: mid
+        path: /path/mid
+`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			writeConfigFile(t, path, tt.content)
+
+			cfg, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() failed: %v", err)
+			}
+			if err := cfg.RenameProject("g", "alpha", "beta"); err != nil {
+				t.Fatalf("RenameProject() failed: %v", err)
+			}
+			if err := cfg.Save(path); err != nil {
+				t.Fatalf("Save() failed: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), "beta") || !strings.Contains(string(data), "# renamed") {
+				t.Errorf("Expected the renamed entry to keep its comment:\n%s", data)
+			}
+
+			loaded, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() of saved config failed: %v", err)
+			}
+			projects, err := loaded.Groups["g"].GetProjectPaths()
+			if err != nil {
+				t.Fatalf("GetProjectPaths() failed: %v", err)
+			}
+			var order []string
+			for _, project := range projects {
+				order = append(order, fmt.Sprintf("%s:%d", project.Alias, project.Priority))
+			}
+			if !reflect.DeepEqual(order, []string{"zeta:1", "beta:2", "mid:3"}) {
+				t.Errorf("Expected order and priorities to be unchanged, got %v\n%s", order, data)
+			}
+		})
+	}
+}
+
+func TestMoveProject(t *testing.T) {
+	newConfig := func() *Config {
+		return &Config{Groups: map[string]*Group{
+			"web": {
+				Paths:    map[string]interface{}{"app": "/path/app", "admin": "/path/admin"},
+				Priority: []string{"app", "admin"},
+				Mappings: map[string][]string{"app": {"a -> b"}},
+			},
+			"api": {
+				Paths: map[string]interface{}{"server": "/path/server"},
+			},
+		}}
+	}
+
+	t.Run("moves path, priority and mappings", func(t *testing.T) {
+		cfg := newConfig()
+		if err := cfg.MoveProject("web", "api", "app"); err != nil {
+			t.Fatalf("MoveProject() failed: %v", err)
+		}
+
+		web, api := cfg.Groups["web"], cfg.Groups["api"]
+		if web.hasProject(cfg, "app") || len(web.Mappings) != 0 || !reflect.DeepEqual(web.Priority, []string{"admin"}) {
+			t.Errorf("Project should be removed from the source group: %+v", web)
+		}
+		if api.Paths.(map[string]interface{})["app"] != "/path/app" {
+			t.Errorf("Project should be added to the target group: %+v", api.Paths)
+		}
+		if !reflect.DeepEqual(api.Mappings["app"], []string{"a -> b"}) {
+			t.Errorf("Mappings should move with the project: %v", api.Mappings)
+		}
+	})
+
+	t.Run("into a version 2 group", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.Groups["v2"] = &Group{Projects: []Project{{Alias: "main", Path: "/path/main"}}}
+		if err := cfg.MoveProject("web", "v2", "app"); err != nil {
+			t.Fatalf("MoveProject() failed: %v", err)
+		}
+		projects := cfg.Groups["v2"].Projects
+		if len(projects) != 2 || projects[1].Alias != "app" || !reflect.DeepEqual(projects[1].Mappings, []string{"a -> b"}) {
+			t.Errorf("Unexpected projects: %+v", projects)
+		}
+	})
+
+	t.Run("nothing changes on error", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.Groups["api"].Paths.(map[string]interface{})["app"] = "/path/other"
+
+		if err := cfg.MoveProject("web", "api", "app"); err == nil {
+			t.Fatal("Expected error for an alias used in the target group")
+		}
+		if !cfg.Groups["web"].hasProject(cfg, "app") || len(cfg.Groups["web"].Mappings) != 1 {
+			t.Error("Source group should be unchanged after a failed move")
+		}
+		if err := cfg.MoveProject("web", "web", "app"); err == nil {
+			t.Error("Expected error when moving within the same group")
+		}
+		if err := cfg.MoveProject("web", "missing", "app"); err == nil {
+			t.Error("Expected error for unknown target group")
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"reflect"
+)
+
+// Schema returns a JSON Schema describing the configuration file, for editor completion.
+// enums lists the allowed values of string settings by YAML key (e.g., "strategy").
+func Schema(enums map[string][]string) map[string]interface{} {
+	schema := typeSchema(reflect.TypeOf(Config{}), enums)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "dot-claude-sync configuration"
+	return schema
+}
+
+// typeSchema returns the JSON Schema for values of type t
+func typeSchema(t reflect.Type, enums map[string][]string) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		for name, field := range yamlFields(t) {
+			var property map[string]interface{}
+			switch {
+			case name == "paths" && field.Type.Kind() == reflect.Interface:
+				// Paths are either alias: path pairs or a list of paths
+				property = map[string]interface{}{
+					"oneOf": []interface{}{
+						map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "string"}},
+						map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					},
+				}
+			default:
+				property = typeSchema(field.Type, enums)
+				if values, ok := enums[name]; ok && field.Type.Kind() == reflect.String {
+					property["enum"] = values
+				}
+			}
+			properties[name] = property
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem(), enums),
+		}
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(t.Elem(), enums),
+		}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	default:
+		return map[string]interface{}{}
+	}
+}
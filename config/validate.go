@@ -0,0 +1,403 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is a problem found in a configuration file
+type ValidationError struct {
+	File    string // Config file containing the problem
+	Line    int    // 1-based line, 0 if unknown
+	Column  int    // 1-based column, 0 if unknown
+	Message string
+}
+
+// Error returns the problem prefixed with its file position
+func (e ValidationError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// validator collects problems found in the loaded configuration files
+type validator struct {
+	cfg          *Config
+	checkPattern func(pattern string) error
+	roots        map[string]*yaml.Node // Top-level mapping node of each file
+	errors       []ValidationError
+}
+
+// Validate checks the loaded configuration and every file it was loaded from: unknown keys,
+// exclude/include/rule patterns (using checkPattern), project paths that are missing or not
+// directories, priority entries that name no project, projects of a group resolving to the
+// same directory, and group settings that cannot be resolved. Problems are sorted by position.
+func (c *Config) Validate(checkPattern func(pattern string) error) ([]ValidationError, error) {
+	v := &validator{cfg: c, checkPattern: checkPattern, roots: make(map[string]*yaml.Node)}
+
+	for _, file := range c.Files() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", file, err)
+		}
+		if len(doc.Content) == 0 {
+			continue // Empty file
+		}
+
+		root := doc.Content[0]
+		v.roots[file] = root
+		v.checkKeys(file, root, reflect.TypeOf(Config{}))
+	}
+
+	if c.Defaults != nil && c.path != "" {
+		defaults := mappingValue(v.roots[c.path], "defaults")
+		v.checkPatterns(c.path, defaults, "exclude", c.Defaults.Exclude)
+		v.checkPatterns(c.path, defaults, "include", c.Defaults.Include)
+		v.checkRules(c.path, defaults, c.Defaults.Rules)
+	}
+
+	for _, name := range c.ListGroups() {
+		v.checkGroup(name)
+	}
+
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		a, b := v.errors[i], v.errors[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return v.errors, nil
+}
+
+// Files returns the files the configuration was loaded from: the main file, then included
+// files, then the repository-local config if it was merged
+func (c *Config) Files() []string {
+	var files []string
+	if c.path != "" {
+		files = append(files, c.path)
+	}
+
+	included := make([]string, 0, len(c.included))
+	for file := range c.included {
+		included = append(included, file)
+	}
+	sort.Strings(included)
+
+	files = append(files, included...)
+	if c.local != "" {
+		files = append(files, c.local)
+	}
+	return files
+}
+
+// report records a problem at node (or at the start of the file when node is nil)
+func (v *validator) report(file string, node *yaml.Node, format string, args ...interface{}) {
+	err := ValidationError{File: file, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		err.Line, err.Column = node.Line, node.Column
+	}
+	v.errors = append(v.errors, err)
+}
+
+// checkKeys reports mapping keys that do not correspond to a field of t
+func (v *validator) checkKeys(file string, node *yaml.Node, t reflect.Type) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return // Type mismatches are reported when loading
+		}
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if !ok {
+				v.report(file, key, "unknown key '%s'", key.Value)
+				continue
+			}
+			v.checkKeys(file, value, field.Type)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			v.checkKeys(file, node.Content[i], t.Elem())
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for _, item := range node.Content {
+			v.checkKeys(file, item, t.Elem())
+		}
+	}
+}
+
+// yamlFields returns the exported fields of a struct type by YAML key
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// checkGroup validates a group's patterns, paths, priority and inherited settings
+func (v *validator) checkGroup(name string) {
+	group := v.cfg.Groups[name]
+	file := v.cfg.Origin(name)
+	groupKey, groupNode := mappingEntry(mappingValue(v.roots[file], "groups"), name)
+
+	v.checkPatterns(file, groupNode, "exclude", group.Exclude)
+	v.checkPatterns(file, groupNode, "include", group.Include)
+	v.checkRules(file, groupNode, group.Rules)
+
+	dir := group.ProjectDir()
+	effective, err := v.cfg.GetEffectiveGroup(name)
+	if err != nil {
+		v.report(file, groupKey, "%v", err)
+	} else {
+		dir = effective.ProjectDir()
+	}
+	invalidPaths := false
+
+	pathsNode := mappingValue(groupNode, "paths")
+	aliases := make(map[string]bool)
+	projectPaths := make(map[string]bool)
+	seen := make(map[string]string) // Real synced directory -> alias
+
+	checkPath := func(alias, path string, node *yaml.Node) {
+		aliases[alias] = true
+		resolved, err := group.resolvePath(path)
+		if err != nil {
+			v.report(file, node, "%v", err)
+			invalidPaths = true
+			return
+		}
+		normalized := normalizeProjectPath(resolved, dir)
+		projectPaths[normalized] = true
+
+		if info, err := os.Stat(resolved); err != nil {
+			v.report(file, node, "path '%s' does not exist", path)
+		} else if !info.IsDir() {
+			v.report(file, node, "path '%s' is not a directory", path)
+		}
+
+		real := normalized
+		if evaluated, err := filepath.EvalSymlinks(normalized); err == nil {
+			real = evaluated
+		} else if abs, err := filepath.Abs(normalized); err == nil {
+			real = abs
+		}
+		if other, ok := seen[real]; ok {
+			v.report(file, node, "'%s' resolves to the same directory as '%s' (%s)", alias, other, real)
+			return
+		}
+		seen[real] = alias
+	}
+
+	switch paths := group.Paths.(type) {
+	case nil:
+		if len(group.Projects) > 0 {
+			if !v.checkProjects(file, mappingValue(groupNode, "projects"), group.Projects, checkPath) {
+				invalidPaths = true
+			}
+		} else if len(group.Extends) == 0 {
+			v.report(file, groupKey, "group '%s' has no projects", name)
+			invalidPaths = true
+		}
+	case map[string]interface{}:
+		for _, alias := range group.PathAliases() {
+			path, ok := paths[alias].(string)
+			_, node := mappingEntry(pathsNode, alias)
+			if !ok {
+				v.report(file, node, "invalid path value for alias '%s'", alias)
+				invalidPaths = true
+				continue
+			}
+			checkPath(alias, path, node)
+		}
+	case []interface{}:
+		var values, normalized []string
+		var nodes []*yaml.Node
+		for i, item := range paths {
+			var node *yaml.Node
+			if pathsNode != nil && pathsNode.Kind == yaml.SequenceNode && i < len(pathsNode.Content) {
+				node = pathsNode.Content[i]
+			}
+			path, ok := item.(string)
+			if !ok {
+				v.report(file, node, "invalid path value at index %d", i)
+				invalidPaths = true
+				continue
+			}
+			resolved, err := group.resolvePath(path)
+			if err != nil {
+				v.report(file, node, "%v", err)
+				invalidPaths = true
+				continue
+			}
+			values = append(values, path)
+			nodes = append(nodes, node)
+			normalized = append(normalized, normalizeProjectPath(resolved, dir))
+		}
+
+		// Aliases are derived as when the group is loaded, so priority entries can name them
+		derived, err := listAliases(normalized, dir)
+		if err != nil {
+			v.report(file, pathsNode, "%v", err)
+			invalidPaths = true
+		}
+		for i, path := range values {
+			alias := filepath.Base(projectRoot(normalized[i], dir))
+			if derived != nil {
+				alias = derived[i]
+			}
+			checkPath(alias, path, nodes[i])
+		}
+	default:
+		v.report(file, pathsNode, "invalid paths format: must be map or list")
+		invalidPaths = true
+	}
+
+	// Problems spanning projects (mappings, extended groups), unless the paths are already broken
+	if effective != nil && !invalidPaths {
+		if _, err := effective.GetProjectPaths(); err != nil {
+			v.report(file, groupKey, "group '%s': %v", name, err)
+		}
+	}
+
+	priorityNode := mappingValue(groupNode, "priority")
+	for i, entry := range group.Priority {
+		if aliases[entry] || projectPaths[entry] {
+			continue
+		}
+		var node *yaml.Node
+		if priorityNode != nil && i < len(priorityNode.Content) {
+			node = priorityNode.Content[i]
+		}
+		v.report(file, node, "priority entry '%s' is not a project in group '%s'", entry, name)
+	}
+}
+
+// checkProjects validates a version 2 project list, calling checkPath for each project
+// with a path. It reports whether the list is well-formed.
+func (v *validator) checkProjects(file string, node *yaml.Node, projects []Project, checkPath func(alias, path string, node *yaml.Node)) bool {
+	valid := true
+	aliases := make(map[string]bool)
+	for i, project := range projects {
+		var item *yaml.Node
+		if node != nil && node.Kind == yaml.SequenceNode && i < len(node.Content) {
+			item = node.Content[i]
+		}
+		pathNode := mappingValue(item, "path")
+		if pathNode == nil {
+			pathNode = item
+		}
+
+		switch {
+		case project.Alias == "":
+			v.report(file, item, "project at index %d has no alias", i)
+			valid = false
+		case aliases[project.Alias]:
+			v.report(file, mappingValue(item, "alias"), "duplicate project alias '%s'", project.Alias)
+			valid = false
+		}
+		aliases[project.Alias] = true
+
+		if project.Path == "" {
+			v.report(file, item, "project '%s' has no path", project.Alias)
+			valid = false
+			continue
+		}
+		checkPath(project.Alias, project.Path, pathNode)
+	}
+	return valid
+}
+
+// checkPatterns reports patterns of the given key that do not compile
+func (v *validator) checkPatterns(file string, parent *yaml.Node, key string, patterns []string) {
+	if v.checkPattern == nil {
+		return
+	}
+
+	node := mappingValue(parent, key)
+	for i, pattern := range patterns {
+		if err := v.checkPattern(pattern); err != nil {
+			var item *yaml.Node
+			if node != nil && i < len(node.Content) {
+				item = node.Content[i]
+			}
+			v.report(file, item, "invalid %s pattern '%s': %v", key, pattern, err)
+		}
+	}
+}
+
+// checkRules reports rules whose pattern does not compile
+func (v *validator) checkRules(file string, parent *yaml.Node, rules []Rule) {
+	if v.checkPattern == nil {
+		return
+	}
+
+	node := mappingValue(parent, "rules")
+	for i, rule := range rules {
+		if err := v.checkPattern(rule.Pattern); err != nil {
+			var item *yaml.Node
+			if node != nil && i < len(node.Content) {
+				item = mappingValue(node.Content[i], "pattern")
+			}
+			v.report(file, item, "invalid rule pattern '%s': %v", rule.Pattern, err)
+		}
+	}
+}
+
+// mappingEntry returns the key and value nodes for key in a mapping node
+func mappingEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// mappingValue returns the value node for key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	_, value := mappingEntry(node, key)
+	return value
+}
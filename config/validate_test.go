@@ -0,0 +1,139 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectA := filepath.Join(tmpDir, "a")
+	if err := os.MkdirAll(filepath.Join(projectA, ".claude"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	notDir := filepath.Join(tmpDir, "file")
+	if err := os.WriteFile(notDir, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	writeConfigFile(t, configPath, `defaults:
+  stratgy: newest
+groups:
+  web:
+    paths:
+      a: `+projectA+`
+      b: `+projectA+`/.claude
+      c: `+filepath.Join(tmpDir, "missing")+`
+      d: `+notDir+`
+    priority: [a, zz]
+    exclude:
+      - "bad"
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	problems, err := cfg.Validate(func(pattern string) error {
+		if pattern == "bad" {
+			return errors.New("syntax error")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	expected := []string{
+		"config.yaml:2:3: unknown key 'stratgy'",
+		"config.yaml:7:10: 'b' resolves to the same directory as 'a'",
+		"config.yaml:8:10: path '" + filepath.Join(tmpDir, "missing") + "' does not exist",
+		"config.yaml:9:10: path '" + notDir + "' is not a directory",
+		"config.yaml:10:19: priority entry 'zz' is not a project in group 'web'",
+		"config.yaml:12:9: invalid exclude pattern 'bad': syntax error",
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %d: %v", len(expected), len(problems), problems)
+	}
+	for i, want := range expected {
+		if got := problems[i].Error(); !strings.Contains(got, want) {
+			t.Errorf("Problem %d: expected %q in %q", i, want, got)
+		}
+	}
+}
+
+func TestValidate_Valid(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	writeConfigFile(t, configPath, "groups:\n  web:\n    paths:\n      a: "+tmpDir+"\n    priority: [a]\n")
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	problems, err := cfg.Validate(nil)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+}
+
+func TestValidate_ListAliases(t *testing.T) {
+	tmpDir := t.TempDir()
+	web := filepath.Join(tmpDir, "web")
+	main := filepath.Join(tmpDir, "one", "app")
+	feature := filepath.Join(tmpDir, "two", "app")
+	if err := os.MkdirAll(web, 0755); err != nil {
+		t.Fatal(err)
+	}
+	initRepo(t, main, "main")
+	initRepo(t, feature, "feature/b")
+
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	writeConfigFile(t, configPath, `groups:
+  g:
+    paths: [`+web+`, `+main+`, `+feature+`]
+    priority: [app-feature-b, web, app]
+`)
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	problems, err := cfg.Validate(nil)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	// web and app-feature-b are derived aliases; app is not, since two projects share it
+	if len(problems) != 1 || !strings.Contains(problems[0].Error(), "priority entry 'app' is not a project") {
+		t.Errorf("Expected only the ambiguous entry to be reported, got %v", problems)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	schema := Schema(map[string][]string{"strategy": {"newest", "json-merge"}})
+
+	groups := schema["properties"].(map[string]interface{})["groups"].(map[string]interface{})
+	group := groups["additionalProperties"].(map[string]interface{})
+	if group["additionalProperties"] != false {
+		t.Error("Unknown group keys should be rejected")
+	}
+
+	properties := group["properties"].(map[string]interface{})
+	if _, ok := properties["paths"].(map[string]interface{})["oneOf"]; !ok {
+		t.Error("Expected paths to accept a map or a list")
+	}
+	strategy := properties["strategy"].(map[string]interface{})
+	if values, ok := strategy["enum"].([]string); !ok || len(values) != 2 {
+		t.Errorf("Expected strategy enum, got %v", strategy)
+	}
+}
@@ -0,0 +1,431 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// blankLineMarker stands in for blank lines while a file is parsed, since YAML nodes do not
+// record them; it is turned back into a blank line when the file is written
+const blankLineMarker = "#dot-claude-sync:blank"
+
+// document is a parsed config file. Edits are merged into its node tree so that comments,
+// key order, quoting and blank lines survive a save.
+type document struct {
+	root   *yaml.Node                   // Document node
+	indent int                          // Indentation used by the file
+	gaps   map[string]map[string]string // Spacing before inline comments, by comment and line content
+}
+
+// parseDocument parses a config file for editing. It returns nil if the file has no
+// top-level mapping to preserve.
+func parseDocument(data []byte) (*document, error) {
+	var plain yaml.Node
+	if err := yaml.Unmarshal(data, &plain); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(plain.Content) == 0 || plain.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	doc := &document{root: &plain, indent: detectIndent(data)}
+	doc.gaps = commentGaps(data, &plain)
+
+	// Blank lines inside multi-line strings are content, so markers cannot be used there
+	if !hasMultilineScalar(&plain) {
+		var marked yaml.Node
+		if err := yaml.Unmarshal(markBlankLines(data), &marked); err == nil {
+			doc.root = &marked
+		}
+	}
+
+	return doc, nil
+}
+
+// update merges value into the document and returns the file content
+func (d *document) update(value interface{}) ([]byte, error) {
+	var updated yaml.Node
+	if err := updated.Encode(value); err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	mergeNode(d.root.Content[0], &updated)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(d.indent)
+	if err := encoder.Encode(d.root); err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	return restoreCommentGaps(unmarkBlankLines(buf.Bytes()), d.gaps), nil
+}
+
+// rename renames an entry of the collection at path (a list of mapping keys from the top
+// level): the key oldName of a mapping, or the item with alias oldName of a sequence. An
+// update then treats the entry as changed rather than as removed and appended, so it keeps
+// its position and comments.
+func (d *document) rename(path []string, oldName, newName string) {
+	node := d.root.Content[0]
+	for _, key := range path {
+		if node = mappingValue(node, key); node == nil {
+			return
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		if key, _ := mappingEntry(node, oldName); key != nil {
+			key.Value = newName
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if alias := mappingValue(item, "alias"); alias != nil && alias.Value == oldName {
+				alias.Value = newName
+			}
+		}
+	}
+}
+
+// setFirst sets the top-level key to value, adding the key at the top of the file if it is
+// missing, so that an update keeps it there rather than appending it
+func (d *document) setFirst(key string, value interface{}) error {
+	root := d.root.Content[0]
+	if mappingValue(root, key) != nil {
+		return nil // Updated in place by the merge
+	}
+
+	var keyNode, valueNode yaml.Node
+	if err := keyNode.Encode(key); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := valueNode.Encode(value); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	// A comment at the top of the file stays above the new key
+	if len(root.Content) > 0 {
+		keyNode.HeadComment, root.Content[0].HeadComment = root.Content[0].HeadComment, ""
+	}
+	root.Content = append([]*yaml.Node{&keyNode, &valueNode}, root.Content...)
+	return nil
+}
+
+// migrateGroup replaces the paths of a version 1 group with its project list, in place, so
+// that an update keeps the position of the entry. The comments of each path entry move to
+// its project: line comments to the project's path, head comments to the project.
+func (d *document) migrateGroup(name string, projects []Project) error {
+	group := mappingValue(mappingValue(d.root.Content[0], "groups"), name)
+	key, paths := mappingEntry(group, "paths")
+	if key == nil {
+		return nil
+	}
+
+	type comments struct{ head, line string }
+	byAlias := make(map[string]comments)
+	byPath := make(map[string]comments)
+	switch paths.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(paths.Content); i += 2 {
+			alias, path := paths.Content[i], paths.Content[i+1]
+			byAlias[alias.Value] = comments{head: alias.HeadComment, line: path.LineComment}
+		}
+	case yaml.SequenceNode:
+		for _, item := range paths.Content {
+			byPath[item.Value] = comments{head: item.HeadComment, line: item.LineComment}
+		}
+	}
+
+	var list yaml.Node
+	if err := list.Encode(projects); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	for i, item := range list.Content {
+		c, ok := byAlias[projects[i].Alias]
+		if !ok {
+			c = byPath[projects[i].Path]
+		}
+		item.HeadComment = c.head
+		if path := mappingValue(item, "path"); path != nil {
+			path.LineComment = c.line
+		}
+	}
+
+	key.Value = "projects"
+	list.HeadComment, list.LineComment, list.FootComment = paths.HeadComment, paths.LineComment, paths.FootComment
+	*paths = list
+	return nil
+}
+
+// mergeNode updates dst to hold the value of src, keeping dst's comments, the order of
+// its existing mapping keys and sequence items, and the style of unchanged scalars
+func mergeNode(dst, src *yaml.Node) {
+	if isEmptyNode(dst) && isEmptyNode(src) {
+		return // e.g. "exclude:" and "exclude: []" mean the same
+	}
+	if dst.Kind != src.Kind {
+		head, line, foot := dst.HeadComment, dst.LineComment, dst.FootComment
+		*dst = *src
+		dst.HeadComment, dst.LineComment, dst.FootComment = head, line, foot
+		return
+	}
+
+	switch dst.Kind {
+	case yaml.MappingNode:
+		mergeMapping(dst, src)
+	case yaml.SequenceNode:
+		mergeSequence(dst, src)
+	case yaml.ScalarNode:
+		if dst.ShortTag() != src.ShortTag() {
+			dst.Tag, dst.Style = src.Tag, src.Style
+		}
+		dst.Value = src.Value
+	}
+}
+
+// mergeMapping merges the pairs of src into dst. Keys missing from src are removed, and new
+// keys are appended unless their value is empty (an empty value is the same as no key).
+func mergeMapping(dst, src *yaml.Node) {
+	values := make(map[string]*yaml.Node, len(src.Content)/2)
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		values[src.Content[i].Value] = src.Content[i+1]
+	}
+
+	var content []*yaml.Node
+	existing := make(map[string]bool)
+	for i := 0; i+1 < len(dst.Content); i += 2 {
+		key, value := dst.Content[i], dst.Content[i+1]
+		updated, ok := values[key.Value]
+		if !ok {
+			continue
+		}
+		mergeNode(value, updated)
+		content = append(content, key, value)
+		existing[key.Value] = true
+	}
+
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		if existing[key.Value] || isEmptyNode(value) {
+			continue
+		}
+		content = append(content, key, value)
+	}
+
+	if len(dst.Content) >= 2 && len(content) >= 2 {
+		moveFootComment(dst.Content[len(dst.Content)-2], content[len(content)-2])
+	}
+	dst.Content = content
+}
+
+// mergeSequence merges the items of src into dst. Items are matched by value for scalars,
+// by alias or pattern for mappings that have one, and by position otherwise, so moved or
+// kept items retain their comments.
+func mergeSequence(dst, src *yaml.Node) {
+	used := make([]bool, len(dst.Content))
+	content := make([]*yaml.Node, 0, len(src.Content))
+
+	for i, item := range src.Content {
+		match := -1
+		for j, candidate := range dst.Content {
+			if !used[j] && sameItem(candidate, item) {
+				match = j
+				break
+			}
+		}
+		if match < 0 && item.Kind != yaml.ScalarNode && itemKey(item) == "" &&
+			i < len(dst.Content) && !used[i] && dst.Content[i].Kind == item.Kind {
+			match = i
+		}
+
+		if match < 0 {
+			content = append(content, item)
+			continue
+		}
+		used[match] = true
+		mergeNode(dst.Content[match], item)
+		content = append(content, dst.Content[match])
+	}
+
+	if len(dst.Content) > 0 && len(content) > 0 {
+		moveFootComment(dst.Content[len(dst.Content)-1], content[len(content)-1])
+	}
+	dst.Content = content
+}
+
+// moveFootComment moves the comment after the last entry of a collection (such as a
+// trailing blank line) to its new last entry, so that appended entries come before it
+func moveFootComment(oldLast, newLast *yaml.Node) {
+	if oldLast == newLast || oldLast.FootComment == "" || newLast.FootComment != "" {
+		return
+	}
+	newLast.FootComment, oldLast.FootComment = oldLast.FootComment, ""
+}
+
+// sameItem reports whether two sequence items are the same entry
+func sameItem(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind {
+		return false
+	}
+	switch a.Kind {
+	case yaml.ScalarNode:
+		return a.Value == b.Value
+	case yaml.MappingNode:
+		key := itemKey(b)
+		return key != "" && itemKey(a) == key
+	default:
+		return false
+	}
+}
+
+// itemKey returns the value identifying a mapping in a list (its alias or pattern)
+func itemKey(node *yaml.Node) string {
+	if node.Kind != yaml.MappingNode {
+		return ""
+	}
+	for _, key := range []string{"alias", "pattern"} {
+		if value := mappingValue(node, key); value != nil && value.Kind == yaml.ScalarNode {
+			return key + "=" + value.Value
+		}
+	}
+	return ""
+}
+
+// isEmptyNode reports whether a node is null or an empty collection
+func isEmptyNode(node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		return len(node.Content) == 0
+	case yaml.ScalarNode:
+		return node.ShortTag() == "!!null"
+	default:
+		return false
+	}
+}
+
+// hasMultilineScalar reports whether any scalar in the tree spans several lines
+func hasMultilineScalar(node *yaml.Node) bool {
+	if node.Kind == yaml.ScalarNode {
+		return node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || strings.Contains(node.Value, "\n")
+	}
+	for _, child := range node.Content {
+		if hasMultilineScalar(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectIndent returns the indentation of the first indented line, or 4 (the YAML
+// library default) if there is none
+func detectIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || trimmed == line || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if indent := len(line) - len(trimmed); indent >= 2 {
+			return indent
+		}
+		return 2
+	}
+	return 4
+}
+
+// markBlankLines replaces blank lines with blankLineMarker comments
+func markBlankLines(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if i < len(lines)-1 && strings.TrimSpace(line) == "" {
+			lines[i] = blankLineMarker
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// unmarkBlankLines turns blankLineMarker comments back into blank lines
+func unmarkBlankLines(data []byte) []byte {
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == blankLineMarker {
+			lines[i] = ""
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// commentGaps records the spacing before each inline comment of the file that is not the
+// single space the YAML library writes, so that aligned comments keep their column
+func commentGaps(data []byte, root *yaml.Node) map[string]map[string]string {
+	comments := make(map[string]bool)
+	collectLineComments(root, comments)
+
+	gaps := make(map[string]map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		for comment := range comments {
+			code, gap, ok := splitLineComment(line, comment)
+			if !ok || gap == " " {
+				continue
+			}
+			if gaps[comment] == nil {
+				gaps[comment] = make(map[string]string)
+			}
+			gaps[comment][strings.TrimSpace(code)] = gap
+		}
+	}
+	return gaps
+}
+
+// restoreCommentGaps puts back the spacing recorded by commentGaps on lines whose content
+// and comment are unchanged
+func restoreCommentGaps(data []byte, gaps map[string]map[string]string) []byte {
+	if len(gaps) == 0 {
+		return data
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		for comment, byCode := range gaps {
+			code, _, ok := splitLineComment(line, comment)
+			if !ok {
+				continue
+			}
+			if gap, ok := byCode[strings.TrimSpace(code)]; ok {
+				lines[i] = code + gap + comment
+				break
+			}
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// splitLineComment splits a line ending with comment into the content before it and the
+// whitespace in between. It reports false if the line does not end with the comment or has
+// nothing before it.
+func splitLineComment(line, comment string) (code, gap string, ok bool) {
+	rest, found := strings.CutSuffix(strings.TrimRight(line, " \t\r"), comment)
+	if !found {
+		return "", "", false
+	}
+	code = strings.TrimRight(rest, " \t")
+	gap = rest[len(code):]
+	if gap == "" || strings.TrimSpace(code) == "" {
+		return "", "", false
+	}
+	return code, gap, true
+}
+
+// collectLineComments adds the inline comments of the tree to comments
+func collectLineComments(node *yaml.Node, comments map[string]bool) {
+	if node.LineComment != "" && !strings.Contains(node.LineComment, "\n") {
+		comments[node.LineComment] = true
+	}
+	for _, child := range node.Content {
+		collectLineComments(child, comments)
+	}
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const commentedConfig = `# Team configuration
+groups:
+  # Web projects
+  web:
+    paths:
+      main: /path/web/main # primary checkout
+      feature: /path/web/feature
+
+    priority:
+      - main
+    strategy: newest
+
+  # Older group
+  api:
+    paths: [/path/api]
+`
+
+func TestSave_PreservesFormatting(t *testing.T) {
+	t.Run("unchanged config round-trips byte for byte", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		writeConfigFile(t, path, commentedConfig)
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if err := cfg.Save(path); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != commentedConfig {
+			t.Errorf("config changed on save:\n%s", data)
+		}
+	})
+
+	t.Run("edits keep comments, key order and blank lines", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		writeConfigFile(t, path, commentedConfig)
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if err := cfg.AddProject("web", "hotfix", "/path/web/hotfix"); err != nil {
+			t.Fatalf("AddProject() failed: %v", err)
+		}
+		if err := cfg.SetPriority("web", []string{"feature", "main"}); err != nil {
+			t.Fatalf("SetPriority() failed: %v", err)
+		}
+		if err := cfg.Save(path); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		content := string(data)
+
+		for _, want := range []string{
+			"# Team configuration\n",
+			"  # Web projects\n",
+			"main: /path/web/main # primary checkout\n",
+			"      hotfix: /path/web/hotfix\n\n    priority:",
+			"  # Older group\n",
+		} {
+			if !strings.Contains(content, want) {
+				t.Errorf("saved config should contain %q:\n%s", want, content)
+			}
+		}
+
+		// Keys keep their original order instead of the struct field order
+		if strings.Index(content, "web:") > strings.Index(content, "api:") {
+			t.Errorf("group order changed:\n%s", content)
+		}
+		if strings.Index(content, "priority:") > strings.Index(content, "strategy:") {
+			t.Errorf("key order changed:\n%s", content)
+		}
+		if strings.Index(content, "- feature") > strings.Index(content, "- main") {
+			t.Errorf("priority should be updated:\n%s", content)
+		}
+
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() of saved config failed: %v", err)
+		}
+		projects, err := loaded.Groups["web"].GetProjectPaths()
+		if err != nil {
+			t.Fatalf("GetProjectPaths() failed: %v", err)
+		}
+		if len(projects) != 3 {
+			t.Errorf("expected 3 projects, got %d", len(projects))
+		}
+	})
+
+	t.Run("multiline scalars survive edits", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := `groups:
+  web:
+    paths:
+      main: /path/web/main
+    vars:
+      header: |
+        line one
+
+        line three
+`
+		writeConfigFile(t, path, content)
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if err := cfg.AddProject("web", "feature", "/path/web/feature"); err != nil {
+			t.Fatalf("AddProject() failed: %v", err)
+		}
+		if err := cfg.Save(path); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() of saved config failed: %v", err)
+		}
+		if got := loaded.Groups["web"].Vars["header"]; got != "line one\n\nline three\n" {
+			t.Errorf("multiline var = %q", got)
+		}
+	})
+	t.Run("aligned inline comments keep their column", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		writeConfigFile(t, path, `groups:
+  web:
+    paths:
+      a: /path/a       # first
+      bbb: /path/bbb   # second
+    strategy: newest   # default
+`)
+
+		cfg, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() failed: %v", err)
+		}
+		if err := cfg.AddProject("web", "c", "/path/c"); err != nil {
+			t.Fatalf("AddProject() failed: %v", err)
+		}
+		if err := cfg.Save(path); err != nil {
+			t.Fatalf("Save() failed: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := `groups:
+  web:
+    paths:
+      a: /path/a       # first
+      bbb: /path/bbb   # second
+      c: /path/c
+    strategy: newest   # default
+`
+		if string(data) != expected {
+			t.Errorf("expected:\n%s\ngot:\n%s", expected, data)
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/yugo-ibuki/dot-claude-sync/config"
+	"github.com/yugo-ibuki/dot-claude-sync/utils"
 )
 
 // FileInfo represents information about a collected file
@@ -17,47 +18,145 @@ type FileInfo struct {
 	Project  string    // Project alias
 	Priority int       // Project priority
 	ModTime  time.Time // File modification time
+	Layer    int       // Project layer; files from extended groups (higher layers) only fill gaps
+}
+
+// BinaryPolicy controls how binary files are collected
+type BinaryPolicy string
+
+const (
+	// BinarySync collects binary files like any other file
+	BinarySync BinaryPolicy = "sync"
+	// BinarySkip leaves binary files out and reports them as skipped
+	BinarySkip BinaryPolicy = "skip"
+	// BinaryWarn collects binary files but prints a warning for each
+	BinaryWarn BinaryPolicy = "warn"
+)
+
+// ParseBinaryPolicy converts a policy name to a BinaryPolicy.
+// An empty name selects the default policy (sync).
+func ParseBinaryPolicy(name string) (BinaryPolicy, error) {
+	switch BinaryPolicy(name) {
+	case "":
+		return BinarySync, nil
+	case BinarySync, BinarySkip, BinaryWarn:
+		return BinaryPolicy(name), nil
+	default:
+		return "", fmt.Errorf("unknown binary policy '%s' (available: sync, skip, warn)", name)
+	}
+}
+
+// CollectOptions controls which files are collected from each project
+type CollectOptions struct {
+	Exclude     []string     // Exclude patterns (gitignore format)
+	Include     []string     // Include patterns (gitignore format); empty includes everything
+	Overlay     string       // Project-local overlay directory relative to .claude; empty disables overlays
+	MaxFileSize int64        // Files larger than this many bytes are skipped; 0 means no limit
+	Binary      BinaryPolicy // How binary files are handled (default: sync)
+	RootFiles   []string     // Files relative to the project root (outside Dir) to collect as well
+	Dir         string       // Synced directory relative to the project root; empty means .claude
+}
+
+// SkippedFile represents a file left out by the size limit or binary policy
+type SkippedFile struct {
+	RelPath string // Relative path from .claude directory
+	Project string // Project alias
+	Size    int64  // File size in bytes
+	Reason  string // Why the file was skipped
 }
 
 // CollectFiles collects all files from .claude directories across projects
 func CollectFiles(projects []config.ProjectPath, excludePatterns []string) ([]FileInfo, error) {
+	files, _, err := CollectFilesWithReport(projects, CollectOptions{Exclude: excludePatterns})
+	return files, err
+}
+
+// CollectFilesWithReport collects all files from .claude directories across projects using
+// the given collect options and also returns the files left out by the size limit or binary policy
+func CollectFilesWithReport(projects []config.ProjectPath, opts CollectOptions) ([]FileInfo, []SkippedFile, error) {
+	// Validate patterns up front so a typo fails the whole operation
+	if _, err := NewMatcher(opts.Exclude); err != nil {
+		return nil, nil, fmt.Errorf("invalid exclude pattern: %w", err)
+	}
+	if _, err := NewMatcher(opts.Include); err != nil {
+		return nil, nil, fmt.Errorf("invalid include pattern: %w", err)
+	}
+	if opts.Overlay != "" {
+		overlay, err := NormalizeOverlayDir(opts.Overlay)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts.Overlay = overlay
+	}
+	if _, err := ParseBinaryPolicy(string(opts.Binary)); err != nil {
+		return nil, nil, err
+	}
+	rootFiles, err := NormalizeRootFiles(opts.RootFiles, opts.Dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts.RootFiles = rootFiles
+	for _, project := range projects {
+		if _, err := projectMappings(project); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	var allFiles []FileInfo
+	var allSkipped []SkippedFile
 
 	for _, project := range projects {
-		files, err := collectFromProject(project, excludePatterns)
+		files, skipped, err := collectFromProject(project, opts)
 		if err != nil {
 			// Don't fail the entire operation if one project fails
 			fmt.Fprintf(os.Stderr, "Warning: failed to collect from %s: %v\n", project.Alias, err)
 			continue
 		}
 		allFiles = append(allFiles, files...)
+		allSkipped = append(allSkipped, skipped...)
 	}
 
 	if len(allFiles) == 0 {
-		return nil, fmt.Errorf("no files collected from any project")
+		return nil, allSkipped, fmt.Errorf("no files collected from any project")
 	}
 
-	return allFiles, nil
+	return allFiles, allSkipped, nil
 }
 
 // collectFromProject collects files from a single project's .claude directory
-func collectFromProject(project config.ProjectPath, excludePatterns []string) ([]FileInfo, error) {
-	claudeDir := expandPath(project.Path)
+func collectFromProject(project config.ProjectPath, opts CollectOptions) ([]FileInfo, []SkippedFile, error) {
+	claudeDir := utils.ExpandHome(project.Path)
 
-	// Check if .claude directory exists
+	// Check if the synced directory exists
 	info, err := os.Stat(claudeDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil, fmt.Errorf(".claude directory does not exist: %s", claudeDir)
+			return nil, nil, fmt.Errorf("%s directory does not exist: %s", SyncedDirName(project), claudeDir)
 		}
-		return nil, fmt.Errorf("failed to stat .claude directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to stat .claude directory: %w", err)
 	}
 
 	if !info.IsDir() {
-		return nil, fmt.Errorf("path is not a directory: %s", claudeDir)
+		return nil, nil, fmt.Errorf("path is not a directory: %s", claudeDir)
+	}
+
+	matcher, err := loadProjectMatcher(claudeDir, opts.Exclude)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	includeMatcher, err := NewMatcher(opts.Include)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid include pattern: %w", err)
+	}
+
+	mappings, err := projectMappings(project)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	var files []FileInfo
+	var skipped []SkippedFile
 
 	// Walk through the .claude directory
 	err = filepath.Walk(claudeDir, func(path string, info os.FileInfo, err error) error {
@@ -75,11 +174,6 @@ func collectFromProject(project config.ProjectPath, excludePatterns []string) ([
 			return filepath.SkipDir
 		}
 
-		// Skip directories
-		if info.IsDir() {
-			return nil
-		}
-
 		// Calculate relative path from .claude directory
 		relPath, err := filepath.Rel(claudeDir, path)
 		if err != nil {
@@ -89,90 +183,134 @@ func collectFromProject(project config.ProjectPath, excludePatterns []string) ([
 		// Normalize path separators to forward slashes
 		relPath = filepath.ToSlash(relPath)
 
+		// The overlay directory is project-local and never synced
+		if info.IsDir() && opts.Overlay != "" && relPath == opts.Overlay {
+			return filepath.SkipDir
+		}
+
+		// Skip excluded directories entirely; their contents cannot be re-included
+		if info.IsDir() {
+			if matcher.Match(relPath, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// The ignore file is project-local and never synced
+		if relPath == IgnoreFileName {
+			return nil
+		}
+
 		// Check if file matches any exclude pattern
-		if shouldExclude(relPath, excludePatterns) {
+		if matcher.Match(relPath, false) {
 			return nil
 		}
 
+		// When include patterns are set, only matching files are collected (Excluded also
+		// matches files under a matched directory, which is what include needs)
+		if len(includeMatcher.patterns) > 0 && !includeMatcher.Excluded(relPath) {
+			return nil
+		}
+
+		// Overlaid files hold this project's local version and are not shared
+		if _, ok := overlayFile(claudeDir, opts.Overlay, relPath); ok {
+			return nil
+		}
+
+		// Enforce the size limit and binary policy
+		skip, err := checkFilePolicy(relPath, path, info, project.Alias, opts)
+		if err != nil {
+			return err
+		}
+		if skip != nil {
+			skipped = append(skipped, *skip)
+			return nil
+		}
+
+		// Files are identified by their canonical path across the group
 		files = append(files, FileInfo{
-			RelPath:  relPath,
+			RelPath:  toCanonical(relPath, mappings),
 			AbsPath:  path,
 			Project:  project.Alias,
 			Priority: project.Priority,
 			ModTime:  info.ModTime(),
+			Layer:    project.Layer,
 		})
 
 		return nil
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to walk directory: %w", err)
+		return nil, nil, fmt.Errorf("failed to walk directory: %w", err)
 	}
 
-	return files, nil
-}
-
-// shouldExclude checks if a file path matches any of the exclude patterns
-func shouldExclude(relPath string, patterns []string) bool {
-	if len(patterns) == 0 {
-		return false
-	}
-
-	for _, pattern := range patterns {
-		// Try matching the full relative path
-		matched, err := filepath.Match(pattern, relPath)
-		if err != nil {
-			// Invalid pattern, skip it
-			continue
-		}
-		if matched {
-			return true
+	// Collect declared files from the project root, next to .claude
+	rootDir := projectRoot(project, claudeDir)
+	for _, rootFile := range opts.RootFiles {
+		path := filepath.Join(rootDir, filepath.FromSlash(rootFile))
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue // Root files are optional in each project
 		}
 
-		// Also try matching against the base name
-		baseName := filepath.Base(relPath)
-		matched, err = filepath.Match(pattern, baseName)
+		relPath := RootFilePrefix + rootFile
+		skip, err := checkFilePolicy(relPath, path, info, project.Alias, opts)
 		if err != nil {
-			continue
+			return nil, nil, err
 		}
-		if matched {
-			return true
+		if skip != nil {
+			skipped = append(skipped, *skip)
+			continue
 		}
 
-		// Check if pattern matches any directory component
-		// For patterns like "temp/*"
-		if strings.Contains(pattern, "/") {
-			matched, err = filepath.Match(pattern, relPath)
-			if err == nil && matched {
-				return true
-			}
-		}
+		files = append(files, FileInfo{
+			RelPath:  relPath,
+			AbsPath:  path,
+			Project:  project.Alias,
+			Priority: project.Priority,
+			ModTime:  info.ModTime(),
+			Layer:    project.Layer,
+		})
 	}
 
-	return false
+	return files, skipped, nil
 }
 
-// expandPath expands ~ to home directory
-func expandPath(path string) string {
-	if len(path) == 0 || path[0] != '~' {
-		return path
+// checkFilePolicy applies the size limit and binary policy to a file.
+// It returns the skipped file if the file must not be collected.
+func checkFilePolicy(relPath, path string, info os.FileInfo, project string, opts CollectOptions) (*SkippedFile, error) {
+	if opts.MaxFileSize > 0 && info.Size() > opts.MaxFileSize {
+		return &SkippedFile{
+			RelPath: relPath,
+			Project: project,
+			Size:    info.Size(),
+			Reason:  fmt.Sprintf("larger than %s", utils.FormatSize(opts.MaxFileSize)),
+		}, nil
 	}
 
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return path
+	if opts.Binary != BinarySkip && opts.Binary != BinaryWarn {
+		return nil, nil
 	}
 
-	if len(path) == 1 {
-		return homeDir
+	binary, err := utils.IsBinaryFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !binary {
+		return nil, nil
 	}
 
-	// Handle both ~/ and ~ followed by path separator
-	if path[1] == '/' || path[1] == filepath.Separator {
-		return filepath.Join(homeDir, path[2:])
+	if opts.Binary == BinarySkip {
+		return &SkippedFile{
+			RelPath: relPath,
+			Project: project,
+			Size:    info.Size(),
+			Reason:  "binary file",
+		}, nil
 	}
 
-	return filepath.Join(homeDir, path[1:])
+	fmt.Fprintf(os.Stderr, "Warning: syncing binary file %s from %s (%s)\n", DisplayPath(relPath), project, utils.FormatSize(info.Size()))
+	return nil, nil
 }
 
 // GroupFilesByRelPath groups files by their relative path
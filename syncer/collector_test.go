@@ -3,6 +3,8 @@ package syncer
 import (
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/yugo-ibuki/dot-claude-sync/config"
@@ -179,7 +181,31 @@ func TestShouldExclude(t *testing.T) {
 			name:     "match nested file",
 			relPath:  "prompts/nested/file.md",
 			patterns: []string{"prompts/*"},
-			want:     false, // filepath.Match doesn't match nested paths with single *
+			want:     true, // files inside an excluded directory are excluded
+		},
+		{
+			name:     "double star matches any depth",
+			relPath:  "a/b/c/file.tmp",
+			patterns: []string{"**/*.tmp"},
+			want:     true,
+		},
+		{
+			name:     "negation re-includes file",
+			relPath:  "prompts/keep.md",
+			patterns: []string{"*.md", "!keep.md"},
+			want:     false,
+		},
+		{
+			name:     "negation cannot re-include inside excluded directory",
+			relPath:  "temp/keep.md",
+			patterns: []string{"temp/", "!keep.md"},
+			want:     true,
+		},
+		{
+			name:     "anchored pattern does not match nested path",
+			relPath:  "nested/temp/debug.log",
+			patterns: []string{"/temp"},
+			want:     false,
 		},
 		{
 			name:     "match multiple patterns",
@@ -203,10 +229,177 @@ func TestShouldExclude(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := shouldExclude(tt.relPath, tt.patterns)
-			if got != tt.want {
-				t.Errorf("shouldExclude(%q, %v) = %v, want %v", tt.relPath, tt.patterns, got, tt.want)
+			matcher, err := NewMatcher(tt.patterns)
+			if err != nil {
+				t.Fatalf("NewMatcher(%v) failed: %v", tt.patterns, err)
+			}
+			if got := matcher.Excluded(tt.relPath); got != tt.want {
+				t.Errorf("Excluded(%q) with %v = %v, want %v", tt.relPath, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectFilesWithInclude(t *testing.T) {
+	tmpDir := t.TempDir()
+	project1 := filepath.Join(tmpDir, "project1", ".claude")
+
+	for _, relPath := range []string{"commands/build.md", "commands/sub/test.md", "prompts/a.md", "settings.json"} {
+		fullPath := filepath.Join(project1, relPath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(relPath), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects := []config.ProjectPath{
+		{Alias: "project1", Path: project1, Priority: 1},
+	}
+
+	collected, _, err := CollectFilesWithReport(projects, CollectOptions{
+		Include: []string{"commands/", "settings.json"},
+		Exclude: []string{"sub/"},
+	})
+	if err != nil {
+		t.Fatalf("CollectFilesWithReport failed: %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, file := range collected {
+		got[file.RelPath] = true
+	}
+
+	if len(got) != 2 || !got["commands/build.md"] || !got["settings.json"] {
+		t.Errorf("Expected commands/build.md and settings.json, got %v", got)
+	}
+}
+
+func TestCollectFilesWithBuiltinExcludes(t *testing.T) {
+	tmpDir := t.TempDir()
+	project1 := filepath.Join(tmpDir, "project1", ".claude")
+
+	files := []string{
+		"settings.json",
+		"settings.local.json",
+		"CLAUDE.local.md",
+		"todos/abc.json",
+		"shell-snapshots/snap.sh",
+		"commands/todos/list.md",
+		"commands/build.md",
+	}
+	for _, relPath := range files {
+		fullPath := filepath.Join(project1, relPath)
+		if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fullPath, []byte(relPath), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects := []config.ProjectPath{
+		{Alias: "project1", Path: project1, Priority: 1},
+	}
+
+	collected, err := CollectFiles(projects, config.BuiltinExcludes)
+	if err != nil {
+		t.Fatalf("CollectFiles failed: %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, file := range collected {
+		got[file.RelPath] = true
+	}
+
+	// Runtime directories are only excluded at the .claude root
+	for _, want := range []string{"settings.json", "commands/todos/list.md", "commands/build.md"} {
+		if !got[want] {
+			t.Errorf("Expected %s to be collected", want)
+		}
+	}
+	if len(got) != 3 {
+		t.Errorf("Expected 3 files, got %v", got)
+	}
+}
+
+func TestCollectFilesWithReport_SizeAndBinaryPolicy(t *testing.T) {
+	tmpDir := t.TempDir()
+	claudeDir := filepath.Join(tmpDir, "project", ".claude")
+	if err := os.MkdirAll(claudeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string][]byte{
+		"prompt.md":   []byte("small text"),
+		"dataset.csv": []byte(strings.Repeat("x", 2048)),
+		"image.png":   {0x89, 'P', 'N', 'G', 0x00, 0x01},
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(claudeDir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects := []config.ProjectPath{{Alias: "project", Path: claudeDir, Priority: 1}}
+
+	tests := []struct {
+		name        string
+		opts        CollectOptions
+		wantFiles   []string
+		wantSkipped []string
+	}{
+		{
+			name:      "no limits",
+			opts:      CollectOptions{},
+			wantFiles: []string{"dataset.csv", "image.png", "prompt.md"},
+		},
+		{
+			name:        "size limit",
+			opts:        CollectOptions{MaxFileSize: 1024},
+			wantFiles:   []string{"image.png", "prompt.md"},
+			wantSkipped: []string{"dataset.csv"},
+		},
+		{
+			name:        "skip binary",
+			opts:        CollectOptions{Binary: BinarySkip},
+			wantFiles:   []string{"dataset.csv", "prompt.md"},
+			wantSkipped: []string{"image.png"},
+		},
+		{
+			name:      "warn binary",
+			opts:      CollectOptions{Binary: BinaryWarn},
+			wantFiles: []string{"dataset.csv", "image.png", "prompt.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collected, skipped, err := CollectFilesWithReport(projects, tt.opts)
+			if err != nil {
+				t.Fatalf("CollectFilesWithReport failed: %v", err)
+			}
+
+			var gotFiles, gotSkipped []string
+			for _, file := range collected {
+				gotFiles = append(gotFiles, file.RelPath)
+			}
+			for _, file := range skipped {
+				gotSkipped = append(gotSkipped, file.RelPath)
+			}
+			sort.Strings(gotFiles)
+
+			if strings.Join(gotFiles, ",") != strings.Join(tt.wantFiles, ",") {
+				t.Errorf("Expected files %v, got %v", tt.wantFiles, gotFiles)
+			}
+			if strings.Join(gotSkipped, ",") != strings.Join(tt.wantSkipped, ",") {
+				t.Errorf("Expected skipped %v, got %v", tt.wantSkipped, gotSkipped)
 			}
 		})
 	}
+
+	if _, _, err := CollectFilesWithReport(projects, CollectOptions{Binary: "maybe"}); err == nil {
+		t.Error("Expected error for unknown binary policy")
+	}
 }
@@ -0,0 +1,223 @@
+package syncer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFileName is the per-project ignore file read from each .claude directory
+const IgnoreFileName = ".claudesyncignore"
+
+// ignorePattern represents a single compiled gitignore-style pattern
+type ignorePattern struct {
+	raw      string   // Original pattern text
+	segments []string // Pattern split on "/"
+	negate   bool     // Pattern started with "!"
+	dirOnly  bool     // Pattern ended with "/"
+}
+
+// Matcher evaluates gitignore-style patterns against relative paths
+type Matcher struct {
+	patterns []ignorePattern
+}
+
+// NewMatcher compiles the given patterns into a Matcher.
+// Patterns follow .gitignore semantics:
+//   - blank lines and lines starting with "#" are ignored
+//   - a leading "!" negates the pattern (re-includes a previously excluded path)
+//   - a trailing "/" only matches directories
+//   - a leading or middle "/" anchors the pattern to the root of the .claude directory,
+//     otherwise the pattern matches at any depth
+//   - "**" matches zero or more directories
+//
+// The last matching pattern wins.
+func NewMatcher(patterns []string) (*Matcher, error) {
+	m := &Matcher{}
+
+	for _, raw := range patterns {
+		p, ok, err := compilePattern(raw)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+
+	return m, nil
+}
+
+// compilePattern parses a single pattern line. ok is false for blank lines and comments.
+func compilePattern(raw string) (ignorePattern, bool, error) {
+	line := strings.TrimRight(raw, " \t\r")
+	// A trailing escaped space is significant
+	if strings.HasSuffix(line, "\\") && len(line) < len(strings.TrimRight(raw, "\r")) {
+		line += " "
+	}
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false, nil
+	}
+
+	p := ignorePattern{raw: raw}
+
+	switch {
+	case strings.HasPrefix(line, "!"):
+		p.negate = true
+		line = line[1:]
+	case strings.HasPrefix(line, "\\!"), strings.HasPrefix(line, "\\#"):
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+
+	if line == "" {
+		return ignorePattern{}, false, fmt.Errorf("invalid pattern %q: empty path", raw)
+	}
+
+	// Patterns containing a slash (other than a trailing one) are anchored
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+
+	segments := strings.Split(line, "/")
+	for _, seg := range segments {
+		if seg == "" {
+			return ignorePattern{}, false, fmt.Errorf("invalid pattern %q: empty path segment", raw)
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return ignorePattern{}, false, fmt.Errorf("invalid pattern %q: %w", raw, err)
+		}
+	}
+
+	if !anchored {
+		segments = append([]string{"**"}, segments...)
+	}
+	p.segments = segments
+
+	return p, true, nil
+}
+
+// Match reports whether relPath itself is matched by the patterns.
+// Parent directories are not considered; use Excluded for that.
+func (m *Matcher) Match(relPath string, isDir bool) bool {
+	if m == nil || len(m.patterns) == 0 {
+		return false
+	}
+
+	segments := strings.Split(NormalizePath(relPath), "/")
+
+	excluded := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(p.segments, segments) {
+			excluded = !p.negate
+		}
+	}
+
+	return excluded
+}
+
+// Excluded reports whether the file at relPath is excluded, either directly
+// or because one of its parent directories is excluded
+func (m *Matcher) Excluded(relPath string) bool {
+	if m == nil || len(m.patterns) == 0 {
+		return false
+	}
+
+	relPath = NormalizePath(relPath)
+	segments := strings.Split(relPath, "/")
+
+	// As with git, a file cannot be re-included if a parent directory is excluded
+	for i := 1; i < len(segments); i++ {
+		if m.Match(strings.Join(segments[:i], "/"), true) {
+			return true
+		}
+	}
+
+	return m.Match(relPath, false)
+}
+
+// matchSegments matches path segments against pattern segments, expanding "**"
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// A trailing "**" matches everything inside, but not the directory itself
+			if len(pattern) == 1 {
+				return len(segments) > 0
+			}
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+
+		matched, err := path.Match(pattern[0], segments[0])
+		if err != nil || !matched {
+			return false
+		}
+
+		pattern = pattern[1:]
+		segments = segments[1:]
+	}
+
+	return len(segments) == 0
+}
+
+// LoadIgnoreFile reads patterns from an ignore file.
+// A missing file is not an error and yields no patterns.
+func LoadIgnoreFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open ignore file: %w", err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ignore file: %w", err)
+	}
+
+	return patterns, nil
+}
+
+// loadProjectMatcher builds the matcher for a project from the group's exclude
+// patterns followed by the project's own .claudesyncignore file
+func loadProjectMatcher(claudeDir string, excludePatterns []string) (*Matcher, error) {
+	filePatterns, err := LoadIgnoreFile(filepath.Join(claudeDir, IgnoreFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := make([]string, 0, len(excludePatterns)+len(filePatterns))
+	patterns = append(patterns, excludePatterns...)
+	patterns = append(patterns, filePatterns...)
+
+	matcher, err := NewMatcher(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern in %s: %w", IgnoreFileName, err)
+	}
+
+	return matcher, nil
+}
@@ -0,0 +1,151 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yugo-ibuki/dot-claude-sync/config"
+)
+
+func TestNewMatcher(t *testing.T) {
+	t.Run("skips comments and blank lines", func(t *testing.T) {
+		matcher, err := NewMatcher([]string{"# comment", "", "   ", "*.tmp"})
+		if err != nil {
+			t.Fatalf("NewMatcher failed: %v", err)
+		}
+		if len(matcher.patterns) != 1 {
+			t.Errorf("Expected 1 pattern, got %d", len(matcher.patterns))
+		}
+	})
+
+	t.Run("rejects invalid pattern", func(t *testing.T) {
+		if _, err := NewMatcher([]string{"[abc"}); err == nil {
+			t.Error("Expected error for invalid pattern")
+		}
+	})
+
+	t.Run("rejects empty negation", func(t *testing.T) {
+		if _, err := NewMatcher([]string{"!"}); err == nil {
+			t.Error("Expected error for empty negated pattern")
+		}
+	})
+}
+
+func TestMatcherExcluded(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		relPath  string
+		want     bool
+	}{
+		{"unanchored matches at any depth", []string{"*.tmp"}, "a/b/c.tmp", true},
+		{"leading double star", []string{"**/*.tmp"}, "c.tmp", true},
+		{"middle double star", []string{"docs/**/draft.md"}, "docs/a/b/draft.md", true},
+		{"middle double star matches zero dirs", []string{"docs/**/draft.md"}, "docs/draft.md", true},
+		{"trailing double star", []string{"temp/**"}, "temp/a/b.log", true},
+		{"anchored with leading slash", []string{"/notes.md"}, "notes.md", true},
+		{"anchored with leading slash nested", []string{"/notes.md"}, "sub/notes.md", false},
+		{"directory pattern excludes contents", []string{"cache/"}, "cache/x/y.json", true},
+		{"directory pattern does not match file", []string{"cache/"}, "cache", false},
+		{"directory pattern matches nested directory", []string{"cache/"}, "a/cache/y.json", true},
+		{"single star does not cross slash", []string{"temp/*.log"}, "temp/sub/a.log", false},
+		{"negation last wins", []string{"*.md", "!keep.md"}, "keep.md", false},
+		{"re-exclude after negation", []string{"*.md", "!keep.md", "keep.md"}, "keep.md", true},
+		{"negation inside excluded directory", []string{"temp/", "!temp/keep.md"}, "temp/keep.md", true},
+		{"escaped bang is literal", []string{"\\!important.md"}, "!important.md", true},
+		{"character class", []string{"file[0-9].txt"}, "file3.txt", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := NewMatcher(tt.patterns)
+			if err != nil {
+				t.Fatalf("NewMatcher failed: %v", err)
+			}
+			if got := matcher.Excluded(tt.relPath); got != tt.want {
+				t.Errorf("Excluded(%q) with %v = %v, want %v", tt.relPath, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectFilesWithIgnoreFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	project1 := filepath.Join(tmpDir, "project1", ".claude")
+	project2 := filepath.Join(tmpDir, "project2", ".claude")
+
+	files := map[string]string{
+		filepath.Join(project1, "prompts", "a.md"):         "a",
+		filepath.Join(project1, "prompts", "keep.md"):      "keep",
+		filepath.Join(project1, "scratch", "notes.md"):     "notes",
+		filepath.Join(project1, "scratch", "deep", "x.md"): "x",
+		filepath.Join(project1, IgnoreFileName):            "scratch/\n*.md\n!keep.md\n",
+		filepath.Join(project2, "prompts", "a.md"):         "a",
+		filepath.Join(project2, "scratch", "notes.md"):     "notes",
+	}
+
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects := []config.ProjectPath{
+		{Alias: "project1", Path: project1, Priority: 1},
+		{Alias: "project2", Path: project2, Priority: 2},
+	}
+
+	collected, err := CollectFiles(projects, nil)
+	if err != nil {
+		t.Fatalf("CollectFiles failed: %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, file := range collected {
+		got[file.Project+":"+file.RelPath] = true
+	}
+
+	want := []string{
+		"project1:prompts/keep.md",
+		"project2:prompts/a.md",
+		"project2:scratch/notes.md", // ignore file only applies to its own project
+	}
+	for _, key := range want {
+		if !got[key] {
+			t.Errorf("Expected %s to be collected", key)
+		}
+	}
+
+	notWant := []string{
+		"project1:prompts/a.md",
+		"project1:scratch/notes.md",
+		"project1:scratch/deep/x.md",
+		"project1:" + IgnoreFileName,
+	}
+	for _, key := range notWant {
+		if got[key] {
+			t.Errorf("Expected %s to be excluded", key)
+		}
+	}
+}
+
+func TestCollectFilesInvalidPattern(t *testing.T) {
+	tmpDir := t.TempDir()
+	project1 := filepath.Join(tmpDir, "project1", ".claude")
+	if err := os.MkdirAll(project1, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	projects := []config.ProjectPath{
+		{Alias: "project1", Path: project1, Priority: 1},
+	}
+
+	if _, err := CollectFiles(projects, []string{"[bad"}); err == nil {
+		t.Error("Expected error for invalid exclude pattern")
+	}
+}
@@ -0,0 +1,351 @@
+package syncer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// KeyConflict represents a JSON key whose value differs between projects
+type KeyConflict struct {
+	Key        string   // Dotted key path (e.g., "permissions.defaultMode")
+	Source     string   // Project whose value was kept
+	Overridden []string // Projects whose differing value was dropped
+}
+
+// jsonObject is a decoded JSON object that preserves key order
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// MergeJSONFiles deep-merges JSON documents from the given candidates.
+// Candidates must be ordered by preference: the first candidate is the base document
+// and wins scalar conflicts. Objects are merged recursively and arrays are unioned,
+// preserving the order in which items first appear. The returned content is nil if
+// merging does not change the base document.
+func MergeJSONFiles(candidates []FileInfo) ([]byte, []KeyConflict, error) {
+	if len(candidates) == 0 {
+		return nil, nil, fmt.Errorf("no files to merge")
+	}
+
+	docs := make([]interface{}, len(candidates))
+	for i, candidate := range candidates {
+		data, err := os.ReadFile(candidate.AbsPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s from %s: %w", DisplayPath(candidate.RelPath), candidate.Project, err)
+		}
+		doc, err := parseJSON(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s from %s: %w", DisplayPath(candidate.RelPath), candidate.Project, err)
+		}
+		docs[i] = doc
+	}
+
+	base, ok := docs[0].(*jsonObject)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s from %s is not a JSON object", DisplayPath(candidates[0].RelPath), candidates[0].Project)
+	}
+
+	m := &jsonMerger{
+		base:      candidates[0].Project,
+		origins:   make(map[string]string),
+		conflicts: make(map[string]*KeyConflict),
+	}
+
+	changed := false
+	for i := 1; i < len(docs); i++ {
+		obj, ok := docs[i].(*jsonObject)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s from %s is not a JSON object", DisplayPath(candidates[i].RelPath), candidates[i].Project)
+		}
+		if m.mergeObject(base, obj, "", candidates[i].Project) {
+			changed = true
+		}
+	}
+
+	conflicts := make([]KeyConflict, 0, len(m.order))
+	for _, key := range m.order {
+		conflicts = append(conflicts, *m.conflicts[key])
+	}
+
+	if !changed {
+		return nil, conflicts, nil
+	}
+
+	var buf bytes.Buffer
+	if err := encodeJSON(&buf, base, ""); err != nil {
+		return nil, nil, err
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), conflicts, nil
+}
+
+// jsonMerger tracks state while merging JSON documents
+type jsonMerger struct {
+	base      string                  // Project of the base document
+	origins   map[string]string       // Key path -> project that contributed it
+	conflicts map[string]*KeyConflict // Key path -> conflict
+	order     []string                // Conflict key paths in discovery order
+}
+
+// mergeObject merges src into dst, returning true if dst was changed
+func (m *jsonMerger) mergeObject(dst, src *jsonObject, prefix, project string) bool {
+	changed := false
+
+	for _, key := range src.keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		srcVal := src.values[key]
+		dstVal, exists := dst.values[key]
+		if !exists {
+			dst.keys = append(dst.keys, key)
+			dst.values[key] = srcVal
+			m.origins[path] = project
+			changed = true
+			continue
+		}
+
+		switch d := dstVal.(type) {
+		case *jsonObject:
+			if s, ok := srcVal.(*jsonObject); ok {
+				if m.mergeObject(d, s, path, project) {
+					changed = true
+				}
+				continue
+			}
+		case []interface{}:
+			if s, ok := srcVal.([]interface{}); ok {
+				if union, added := unionArrays(d, s); added {
+					dst.values[key] = union
+					changed = true
+				}
+				continue
+			}
+		}
+
+		if !jsonEqual(dstVal, srcVal) {
+			m.addConflict(path, project)
+		}
+	}
+
+	return changed
+}
+
+// addConflict records that project's value for path was dropped
+func (m *jsonMerger) addConflict(path, project string) {
+	conflict, ok := m.conflicts[path]
+	if !ok {
+		conflict = &KeyConflict{Key: path, Source: m.originOf(path)}
+		m.conflicts[path] = conflict
+		m.order = append(m.order, path)
+	}
+	conflict.Overridden = append(conflict.Overridden, project)
+}
+
+// originOf returns the project that contributed the value at path
+func (m *jsonMerger) originOf(path string) string {
+	for {
+		if project, ok := m.origins[path]; ok {
+			return project
+		}
+		idx := strings.LastIndex(path, ".")
+		if idx < 0 {
+			return m.base
+		}
+		path = path[:idx]
+	}
+}
+
+// unionArrays appends items from src that are not already in dst
+func unionArrays(dst, src []interface{}) ([]interface{}, bool) {
+	added := false
+	result := dst
+
+	for _, item := range src {
+		found := false
+		for _, existing := range result {
+			if jsonEqual(existing, item) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, item)
+			added = true
+		}
+	}
+
+	return result, added
+}
+
+// jsonEqual reports whether two decoded JSON values are equal, ignoring key order
+func jsonEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case *jsonObject:
+		bv, ok := b.(*jsonObject)
+		if !ok || len(av.keys) != len(bv.keys) {
+			return false
+		}
+		for _, key := range av.keys {
+			bval, exists := bv.values[key]
+			if !exists || !jsonEqual(av.values[key], bval) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+// parseJSON decodes a JSON document, preserving object key order
+func parseJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	value, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+
+	return value, nil
+}
+
+// decodeJSONValue decodes the next value from the decoder
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		// string, json.Number, bool or nil
+		return tok, nil
+	}
+
+	switch delim {
+	case '{':
+		obj := &jsonObject{values: make(map[string]interface{})}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyTok.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid object key: %v", keyTok)
+			}
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := obj.values[key]; !exists {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = value
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case '[':
+		arr := []interface{}{}
+		for dec.More() {
+			value, err := decodeJSONValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return arr, nil
+	default:
+		return nil, fmt.Errorf("unexpected delimiter: %v", delim)
+	}
+}
+
+// encodeJSON writes a decoded JSON value with two-space indentation
+func encodeJSON(buf *bytes.Buffer, value interface{}, indent string) error {
+	switch v := value.(type) {
+	case *jsonObject:
+		if len(v.keys) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		for i, key := range v.keys {
+			buf.WriteString(indent + "  ")
+			if err := encodeJSONScalar(buf, key); err != nil {
+				return err
+			}
+			buf.WriteString(": ")
+			if err := encodeJSON(buf, v.values[key], indent+"  "); err != nil {
+				return err
+			}
+			if i < len(v.keys)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "}")
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, item := range v {
+			buf.WriteString(indent + "  ")
+			if err := encodeJSON(buf, item, indent+"  "); err != nil {
+				return err
+			}
+			if i < len(v)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "]")
+	default:
+		return encodeJSONScalar(buf, v)
+	}
+
+	return nil
+}
+
+// encodeJSONScalar writes a scalar value without HTML escaping
+func encodeJSONScalar(buf *bytes.Buffer, value interface{}) error {
+	var scalar bytes.Buffer
+	enc := json.NewEncoder(&scalar)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode JSON value: %w", err)
+	}
+	buf.Write(bytes.TrimRight(scalar.Bytes(), "\n"))
+	return nil
+}
@@ -0,0 +1,215 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeJSONCandidate writes content to dir/settings.json and returns its FileInfo
+func writeJSONCandidate(t *testing.T, dir, project string, priority int, content string) FileInfo {
+	t.Helper()
+
+	path := filepath.Join(dir, project, "settings.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return FileInfo{
+		RelPath:  "settings.json",
+		AbsPath:  path,
+		Project:  project,
+		Priority: priority,
+		ModTime:  time.Now(),
+	}
+}
+
+func TestMergeJSONFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	base := writeJSONCandidate(t, tmpDir, "p1", 1, `{
+  "model": "opus",
+  "permissions": {
+    "allow": ["Bash(npm test)", "Read"],
+    "defaultMode": "acceptEdits"
+  },
+  "env": {"A": "1"}
+}`)
+	other := writeJSONCandidate(t, tmpDir, "p2", 2, `{
+  "permissions": {
+    "allow": ["Read", "Bash(go test ./...)"],
+    "deny": ["Bash(rm -rf *)"],
+    "defaultMode": "plan"
+  },
+  "env": {"A": "1", "B": "2"},
+  "hooks": {"Stop": [{"command": "echo done && exit 0"}]}
+}`)
+
+	content, conflicts, err := MergeJSONFiles([]FileInfo{base, other})
+	if err != nil {
+		t.Fatalf("MergeJSONFiles failed: %v", err)
+	}
+
+	expected := `{
+  "model": "opus",
+  "permissions": {
+    "allow": [
+      "Bash(npm test)",
+      "Read",
+      "Bash(go test ./...)"
+    ],
+    "defaultMode": "acceptEdits",
+    "deny": [
+      "Bash(rm -rf *)"
+    ]
+  },
+  "env": {
+    "A": "1",
+    "B": "2"
+  },
+  "hooks": {
+    "Stop": [
+      {
+        "command": "echo done && exit 0"
+      }
+    ]
+  }
+}
+`
+	if string(content) != expected {
+		t.Errorf("Unexpected merged content:\n%s\nwant:\n%s", content, expected)
+	}
+
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected 1 key conflict, got %d: %+v", len(conflicts), conflicts)
+	}
+	if conflicts[0].Key != "permissions.defaultMode" || conflicts[0].Source != "p1" {
+		t.Errorf("Unexpected conflict: %+v", conflicts[0])
+	}
+	if len(conflicts[0].Overridden) != 1 || conflicts[0].Overridden[0] != "p2" {
+		t.Errorf("Unexpected overridden projects: %v", conflicts[0].Overridden)
+	}
+}
+
+func TestMergeJSONFiles_ConflictWithAddedKey(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	p1 := writeJSONCandidate(t, tmpDir, "p1", 1, `{"a": 1}`)
+	p2 := writeJSONCandidate(t, tmpDir, "p2", 2, `{"b": {"c": true}}`)
+	p3 := writeJSONCandidate(t, tmpDir, "p3", 3, `{"b": {"c": false}}`)
+
+	content, conflicts, err := MergeJSONFiles([]FileInfo{p1, p2, p3})
+	if err != nil {
+		t.Fatalf("MergeJSONFiles failed: %v", err)
+	}
+
+	if !strings.Contains(string(content), `"c": true`) {
+		t.Errorf("Expected value from p2 to be kept, got:\n%s", content)
+	}
+	if len(conflicts) != 1 || conflicts[0].Source != "p2" || conflicts[0].Overridden[0] != "p3" {
+		t.Errorf("Unexpected conflicts: %+v", conflicts)
+	}
+}
+
+func TestMergeJSONFiles_Unchanged(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	p1 := writeJSONCandidate(t, tmpDir, "p1", 1, `{"allow": ["a", "b"], "x": 1.50}`)
+	p2 := writeJSONCandidate(t, tmpDir, "p2", 2, `{"allow": ["b"], "x": 1.50}`)
+
+	content, conflicts, err := MergeJSONFiles([]FileInfo{p1, p2})
+	if err != nil {
+		t.Fatalf("MergeJSONFiles failed: %v", err)
+	}
+	if content != nil {
+		t.Errorf("Expected nil content when base is unchanged, got:\n%s", content)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts, got %+v", conflicts)
+	}
+}
+
+func TestMergeJSONFiles_Invalid(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	valid := writeJSONCandidate(t, tmpDir, "p1", 1, `{"a": 1}`)
+	invalid := writeJSONCandidate(t, tmpDir, "p2", 2, `{"a": `)
+	array := writeJSONCandidate(t, tmpDir, "p3", 3, `[1, 2]`)
+
+	if _, _, err := MergeJSONFiles([]FileInfo{valid, invalid}); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+	if _, _, err := MergeJSONFiles([]FileInfo{array, valid}); err == nil {
+		t.Error("Expected error for non-object JSON")
+	}
+}
+
+func TestResolveConflicts_SettingsJSONMerged(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	p1 := writeJSONCandidate(t, tmpDir, "p1", 1, `{"permissions": {"allow": ["A"]}}`)
+	p2 := writeJSONCandidate(t, tmpDir, "p2", 2, `{"permissions": {"allow": ["B"]}}`)
+
+	resolved, conflicts, err := ResolveConflicts([]FileInfo{p1, p2}, nil)
+	if err != nil {
+		t.Fatalf("ResolveConflicts failed: %v", err)
+	}
+
+	if len(conflicts) != 1 || conflicts[0].Strategy != StrategyJSONMerge {
+		t.Fatalf("Expected settings.json to be merged, got %+v", conflicts)
+	}
+	if !strings.Contains(string(resolved[0].Content), `"A"`) || !strings.Contains(string(resolved[0].Content), `"B"`) {
+		t.Errorf("Expected merged allow list, got:\n%s", resolved[0].Content)
+	}
+
+	summary := FormatConflict(conflicts[0])
+	if !strings.Contains(summary, "merged from p1, p2") {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+
+	// A configured rule can opt out of merging
+	resolved, conflicts, err = ResolveConflictsWithOptions([]FileInfo{p1, p2}, ResolveOptions{
+		Rules: []Rule{{Pattern: "settings.json", Strategy: StrategyNewest}},
+	})
+	if err != nil {
+		t.Fatalf("ResolveConflictsWithOptions failed: %v", err)
+	}
+	if conflicts[0].Strategy != StrategyNewest || resolved[0].Content != nil || resolved[0].Source != "p1" {
+		t.Errorf("Expected rule to override merge, got %+v", resolved[0])
+	}
+}
+
+func TestSyncFiles_WritesMergedContent(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	p1 := writeJSONCandidate(t, tmpDir, "p1", 1, `{"allow": ["A"]}`)
+	p2 := writeJSONCandidate(t, tmpDir, "p2", 2, `{"allow": ["B"]}`)
+
+	resolved, _, err := ResolveConflicts([]FileInfo{p1, p2}, nil)
+	if err != nil {
+		t.Fatalf("ResolveConflicts failed: %v", err)
+	}
+
+	projects := []struct{ alias, dir string }{
+		{"p1", filepath.Join(tmpDir, "p1")},
+		{"p2", filepath.Join(tmpDir, "p2")},
+	}
+
+	for _, project := range projects {
+		if err := writeResolvedFile(resolved[0], filepath.Join(project.dir, "settings.json")); err != nil {
+			t.Fatalf("writeResolvedFile failed: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(project.dir, "settings.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != string(resolved[0].Content) {
+			t.Errorf("%s: expected merged content, got:\n%s", project.alias, data)
+		}
+	}
+}
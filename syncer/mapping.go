@@ -0,0 +1,134 @@
+package syncer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/yugo-ibuki/dot-claude-sync/config"
+)
+
+// Mapping maps a project-local path to the canonical path used across the group.
+// Directory mappings are written as "local/** -> canonical/**"; file mappings as "a.md -> b.md".
+type Mapping struct {
+	Local     string // Local path, or directory prefix ending in "/" (empty for the root)
+	Canonical string // Canonical path, or directory prefix ending in "/" (empty for the root)
+	Dir       bool   // Whether the mapping applies to everything under a directory
+}
+
+// ParseMapping parses a mapping of the form "local -> canonical"
+func ParseMapping(spec string) (Mapping, error) {
+	parts := strings.Split(spec, "->")
+	if len(parts) != 2 {
+		return Mapping{}, fmt.Errorf("invalid mapping '%s': expected 'local -> canonical'", spec)
+	}
+
+	local, localDir, err := parseMappingSide(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return Mapping{}, fmt.Errorf("invalid mapping '%s': %w", spec, err)
+	}
+	canonical, canonicalDir, err := parseMappingSide(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return Mapping{}, fmt.Errorf("invalid mapping '%s': %w", spec, err)
+	}
+	if localDir != canonicalDir {
+		return Mapping{}, fmt.Errorf("invalid mapping '%s': both sides must end in '/**' or neither", spec)
+	}
+
+	return Mapping{Local: local, Canonical: canonical, Dir: localDir}, nil
+}
+
+// parseMappingSide parses one side of a mapping, returning the path or directory prefix
+func parseMappingSide(side string) (string, bool, error) {
+	if side == "" {
+		return "", false, fmt.Errorf("empty path")
+	}
+
+	dir := false
+	switch {
+	case side == "**":
+		return "", true, nil
+	case strings.HasSuffix(side, "/**"):
+		dir = true
+		side = strings.TrimSuffix(side, "/**")
+	}
+
+	if strings.ContainsAny(side, "*?[") {
+		return "", false, fmt.Errorf("only a trailing '/**' wildcard is supported")
+	}
+
+	cleaned := path.Clean(strings.TrimPrefix(side, "/"))
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", false, fmt.Errorf("path '%s' must be inside the synced directory", side)
+	}
+
+	if dir {
+		return cleaned + "/", true, nil
+	}
+	return cleaned, false, nil
+}
+
+// ParseMappings parses a list of mappings
+func ParseMappings(specs []string) ([]Mapping, error) {
+	mappings := make([]Mapping, 0, len(specs))
+	for _, spec := range specs {
+		mapping, err := ParseMapping(spec)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// projectMappings parses the mappings configured for a project
+func projectMappings(project config.ProjectPath) ([]Mapping, error) {
+	mappings, err := ParseMappings(project.Mappings)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", project.Alias, err)
+	}
+	return mappings, nil
+}
+
+// toCanonical converts a project-local relative path to its canonical path.
+// The first matching mapping wins; unmapped paths are returned unchanged.
+func toCanonical(relPath string, mappings []Mapping) string {
+	if IsRootFile(relPath) {
+		return relPath
+	}
+	for _, m := range mappings {
+		if mapped, ok := m.apply(relPath, m.Local, m.Canonical); ok {
+			return mapped
+		}
+	}
+	return relPath
+}
+
+// toLocal converts a canonical relative path to the project-local path.
+// The first matching mapping wins; unmapped paths are returned unchanged.
+func toLocal(relPath string, mappings []Mapping) string {
+	if IsRootFile(relPath) {
+		return relPath
+	}
+	for _, m := range mappings {
+		if mapped, ok := m.apply(relPath, m.Canonical, m.Local); ok {
+			return mapped
+		}
+	}
+	return relPath
+}
+
+// apply rewrites relPath from the from side of the mapping to the to side
+func (m Mapping) apply(relPath, from, to string) (string, bool) {
+	if !m.Dir {
+		if relPath == from {
+			return to, true
+		}
+		return "", false
+	}
+
+	if !strings.HasPrefix(relPath, from) {
+		return "", false
+	}
+	return to + strings.TrimPrefix(relPath, from), true
+}
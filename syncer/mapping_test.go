@@ -0,0 +1,120 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yugo-ibuki/dot-claude-sync/config"
+)
+
+func TestParseMapping(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Mapping
+		wantErr bool
+	}{
+		{spec: "commands/team/** -> commands/**", want: Mapping{Local: "commands/team/", Canonical: "commands/", Dir: true}},
+		{spec: "shared/** -> **", want: Mapping{Local: "shared/", Canonical: "", Dir: true}},
+		{spec: "NOTES.md->docs/notes.md", want: Mapping{Local: "NOTES.md", Canonical: "docs/notes.md"}},
+		{spec: "commands/** -> commands/team.md", wantErr: true},
+		{spec: "commands/*.md -> prompts/*.md", wantErr: true},
+		{spec: "../outside/** -> commands/**", wantErr: true},
+		{spec: "commands/team/**", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseMapping(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMapping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMappingConversion(t *testing.T) {
+	mappings, err := ParseMappings([]string{"commands/team/** -> commands/**", "NOTES.md -> docs/notes.md"})
+	if err != nil {
+		t.Fatalf("ParseMappings failed: %v", err)
+	}
+
+	tests := []struct {
+		local     string
+		canonical string
+	}{
+		{"commands/team/review.md", "commands/review.md"},
+		{"commands/team/sub/deploy.md", "commands/sub/deploy.md"},
+		{"NOTES.md", "docs/notes.md"},
+		{"prompts/a.md", "prompts/a.md"},
+		{"../CLAUDE.md", "../CLAUDE.md"},
+	}
+
+	for _, tt := range tests {
+		if got := toCanonical(tt.local, mappings); got != tt.canonical {
+			t.Errorf("toCanonical(%q) = %q, want %q", tt.local, got, tt.canonical)
+		}
+		if got := toLocal(tt.canonical, mappings); got != tt.local {
+			t.Errorf("toLocal(%q) = %q, want %q", tt.canonical, got, tt.local)
+		}
+	}
+}
+
+func TestSyncFiles_Mappings(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	standard := filepath.Join(tmpDir, "standard", ".claude")
+	legacy := filepath.Join(tmpDir, "legacy", ".claude")
+	if err := os.MkdirAll(filepath.Join(standard, "commands"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(legacy, "commands", "team"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(standard, "commands", "review.md"), []byte("review"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(legacy, "commands", "team", "deploy.md"), []byte("deploy"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	projects := []config.ProjectPath{
+		{Alias: "standard", Path: standard, Priority: 1},
+		{Alias: "legacy", Path: legacy, Priority: 2, Mappings: []string{"commands/team/** -> commands/**"}},
+	}
+
+	files, err := CollectFiles(projects, nil)
+	if err != nil {
+		t.Fatalf("CollectFiles failed: %v", err)
+	}
+	for _, file := range files {
+		if file.Project == "legacy" && file.RelPath != "commands/deploy.md" {
+			t.Errorf("Expected canonical path commands/deploy.md, got %s", file.RelPath)
+		}
+	}
+
+	resolved, _, err := ResolveConflicts(files, nil)
+	if err != nil {
+		t.Fatalf("ResolveConflicts failed: %v", err)
+	}
+	if _, err := SyncFiles(resolved, projects, false, false, true); err != nil {
+		t.Fatalf("SyncFiles failed: %v", err)
+	}
+
+	for _, path := range []string{
+		filepath.Join(standard, "commands", "review.md"),
+		filepath.Join(standard, "commands", "deploy.md"),
+		filepath.Join(legacy, "commands", "team", "review.md"),
+		filepath.Join(legacy, "commands", "team", "deploy.md"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to exist: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "commands", "review.md")); !os.IsNotExist(err) {
+		t.Error("Files should be written where the mapped project expects them")
+	}
+}
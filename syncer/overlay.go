@@ -0,0 +1,44 @@
+package syncer
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/yugo-ibuki/dot-claude-sync/utils"
+)
+
+// NormalizeOverlayDir validates an overlay directory relative to .claude and returns it
+// in slash-separated form. An empty value means no overlay and is returned as is.
+func NormalizeOverlayDir(dir string) (string, error) {
+	if dir == "" {
+		return "", nil
+	}
+
+	slashed := filepath.ToSlash(dir)
+	if path.IsAbs(slashed) || filepath.IsAbs(dir) {
+		return "", fmt.Errorf("invalid overlay directory '%s': must be relative to .claude", dir)
+	}
+
+	cleaned := path.Clean(slashed)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("invalid overlay directory '%s': must be inside .claude", dir)
+	}
+
+	return cleaned, nil
+}
+
+// overlayFile returns the overlay file that shadows relPath in claudeDir, if it exists
+func overlayFile(claudeDir, overlayDir, relPath string) (string, bool) {
+	if overlayDir == "" || IsRootFile(relPath) {
+		return "", false // Root files live outside .claude and cannot be overlaid
+	}
+
+	overlayPath := filepath.Join(claudeDir, filepath.FromSlash(overlayDir), filepath.FromSlash(relPath))
+	if !utils.FileExists(overlayPath) || utils.IsDirectory(overlayPath) {
+		return "", false
+	}
+
+	return overlayPath, true
+}
@@ -0,0 +1,153 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yugo-ibuki/dot-claude-sync/config"
+)
+
+func TestNormalizeOverlayDir(t *testing.T) {
+	tests := []struct {
+		dir     string
+		want    string
+		wantErr bool
+	}{
+		{dir: "", want: ""}, // No overlay
+		{dir: "local", want: "local"},
+		{dir: "private/overrides/", want: "private/overrides"},
+		{dir: "./mine", want: "mine"},
+		{dir: "/abs", wantErr: true},
+		{dir: "../outside", wantErr: true},
+		{dir: ".", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeOverlayDir(tt.dir)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeOverlayDir(%q) error = %v, wantErr %v", tt.dir, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeOverlayDir(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestSyncFiles_Overlay(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	mainDir := filepath.Join(tmpDir, "main", ".claude")
+	feature := filepath.Join(tmpDir, "feature", ".claude")
+	files := map[string]string{
+		filepath.Join(mainDir, "CLAUDE.md"):                "shared\n",
+		filepath.Join(mainDir, "commands", "review.md"):    "review\n",
+		filepath.Join(feature, "CLAUDE.md"):                "feature version\n",
+		filepath.Join(feature, "local", "CLAUDE.md"):       "overlay version\n",
+		filepath.Join(feature, "local", "notes", "own.md"): "not synced\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects := []config.ProjectPath{
+		{Alias: "main", Path: mainDir, Priority: 2},
+		{Alias: "feature", Path: feature, Priority: 1},
+	}
+
+	collected, _, err := CollectFilesWithReport(projects, CollectOptions{Overlay: "local"})
+	if err != nil {
+		t.Fatalf("CollectFilesWithReport failed: %v", err)
+	}
+	for _, file := range collected {
+		if file.Project == "feature" {
+			t.Errorf("Expected no shared files from feature, got %s", file.RelPath)
+		}
+	}
+
+	resolved, conflicts, err := ResolveConflicts(collected, nil)
+	if err != nil {
+		t.Fatalf("ResolveConflicts failed: %v", err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("Expected overlaid file not to conflict, got %+v", conflicts)
+	}
+
+	results, err := SyncFilesWithOptions(resolved, projects, SyncOptions{Overlay: "local"})
+	if err != nil {
+		t.Fatalf("SyncFilesWithOptions failed: %v", err)
+	}
+
+	// The overlaid path is left alone rather than replaced with either version
+	expected := map[string]string{
+		filepath.Join(mainDir, "CLAUDE.md"):             "shared\n",
+		filepath.Join(feature, "CLAUDE.md"):             "feature version\n",
+		filepath.Join(feature, "commands", "review.md"): "review\n",
+	}
+	for path, want := range expected {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s: expected %q, got %q", path, want, string(got))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(mainDir, "local")); !os.IsNotExist(err) {
+		t.Error("Overlay directory should not be synced")
+	}
+
+	for _, result := range results {
+		wantOverlaid := 0
+		if result.Project == "feature" {
+			wantOverlaid = 1
+		}
+		if result.Overlaid != wantOverlaid {
+			t.Errorf("%s: expected %d overlaid, got %d", result.Project, wantOverlaid, result.Overlaid)
+		}
+	}
+}
+
+func TestSyncFiles_NoOverlayByDefault(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	mainDir := filepath.Join(tmpDir, "main", ".claude")
+	feature := filepath.Join(tmpDir, "feature", ".claude")
+	if err := os.MkdirAll(filepath.Join(mainDir, "local"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(feature, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mainDir, "local", "notes.md"), []byte("notes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	projects := []config.ProjectPath{
+		{Alias: "main", Path: mainDir, Priority: 1},
+		{Alias: "feature", Path: feature, Priority: 2},
+	}
+
+	// Without overlay set, local/ is an ordinary directory and is synced
+	collected, _, err := CollectFilesWithReport(projects, CollectOptions{})
+	if err != nil {
+		t.Fatalf("CollectFilesWithReport failed: %v", err)
+	}
+	resolved, _, err := ResolveConflicts(collected, nil)
+	if err != nil {
+		t.Fatalf("ResolveConflicts failed: %v", err)
+	}
+	if _, err := SyncFilesWithOptions(resolved, projects, SyncOptions{}); err != nil {
+		t.Fatalf("SyncFilesWithOptions failed: %v", err)
+	}
+
+	if got, err := os.ReadFile(filepath.Join(feature, "local", "notes.md")); err != nil || string(got) != "notes\n" {
+		t.Errorf("Expected local/notes.md to be synced, got %q (%v)", got, err)
+	}
+}
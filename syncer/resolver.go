@@ -2,6 +2,7 @@ package syncer
 
 import (
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"time"
@@ -9,26 +10,125 @@ import (
 
 // ResolvedFile represents a file after conflict resolution
 type ResolvedFile struct {
-	RelPath  string // Relative path from .claude directory
-	AbsPath  string // Absolute path to the source file
-	Source   string // Source project alias
-	Priority int    // Priority of the source project
+	RelPath  string   // Relative path from .claude directory
+	AbsPath  string   // Absolute path to the source file
+	Source   string   // Source project alias
+	Priority int      // Priority of the source project
+	Content  []byte   // Generated content (e.g., merged JSON); nil means copy AbsPath
+	Sections []string // Shared sections spliced into each destination; nil unless shared-sections
 }
 
 // Conflict represents a conflict between multiple files with the same path
 type Conflict struct {
-	RelPath    string     // The conflicting relative path
-	Candidates []FileInfo // All candidate files
-	Resolved   FileInfo   // The resolved file (highest priority)
+	RelPath      string        // The conflicting relative path
+	Candidates   []FileInfo    // All candidate files
+	Resolved     FileInfo      // The resolved file (highest priority)
+	Strategy     Strategy      // Strategy used to resolve the conflict
+	KeyConflicts []KeyConflict // Key-level conflicts when the files were merged
+}
+
+// Strategy represents a conflict resolution strategy
+type Strategy string
+
+const (
+	// StrategyNewest picks the newest file, falling back to priority for similar timestamps
+	StrategyNewest Strategy = "newest"
+	// StrategyJSONMerge deep-merges JSON objects and unions arrays across all candidates
+	StrategyJSONMerge Strategy = "json-merge"
+	// StrategySharedSections syncs only marker-delimited sections, leaving the rest of each file alone
+	StrategySharedSections Strategy = "shared-sections"
+)
+
+// Strategies lists all supported conflict resolution strategies
+var Strategies = []Strategy{StrategyNewest, StrategyJSONMerge, StrategySharedSections}
+
+// Rule assigns a resolution strategy to files matching a pattern (gitignore format)
+type Rule struct {
+	Pattern  string
+	Strategy Strategy
+}
+
+// DefaultRules are applied before any configured rules.
+// Claude's settings.json is structured, so it is merged rather than replaced.
+var DefaultRules = []Rule{
+	{Pattern: "/settings.json", Strategy: StrategyJSONMerge},
+}
+
+// ParseStrategy converts a strategy name to a Strategy.
+// An empty name selects the default strategy (newest).
+func ParseStrategy(name string) (Strategy, error) {
+	if name == "" {
+		return StrategyNewest, nil
+	}
+
+	for _, s := range Strategies {
+		if Strategy(name) == s {
+			return s, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown resolution strategy '%s' (available: %v)", name, Strategies)
+}
+
+// ResolveOptions controls how conflicts are resolved
+type ResolveOptions struct {
+	Strategy Strategy // Conflict resolution strategy (default: newest)
+	Folders  []string // Folders resolved by modification time only (ignoring priority)
+	Rules    []Rule   // Per-path strategies applied after DefaultRules; the last match wins
+}
+
+// compiledRule is a Rule with its pattern compiled
+type compiledRule struct {
+	matcher  *Matcher
+	strategy Strategy
+}
+
+// compileRules compiles DefaultRules followed by the given rules
+func compileRules(rules []Rule) ([]compiledRule, error) {
+	all := append(append([]Rule{}, DefaultRules...), rules...)
+
+	compiled := make([]compiledRule, 0, len(all))
+	for _, rule := range all {
+		matcher, err := NewMatcher([]string{rule.Pattern})
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule pattern: %w", err)
+		}
+		compiled = append(compiled, compiledRule{matcher: matcher, strategy: rule.Strategy})
+	}
+
+	return compiled, nil
+}
+
+// strategyFor returns the strategy for relPath: the last matching rule, or the fallback
+func strategyFor(relPath string, rules []compiledRule, fallback Strategy) Strategy {
+	strategy := fallback
+	for _, rule := range rules {
+		if rule.matcher.Excluded(relPath) {
+			strategy = rule.strategy
+		}
+	}
+	return strategy
 }
 
 // ResolveConflicts resolves conflicts between files based on priority
 // folderFilter: list of folder names to resolve by modification time only (ignoring priority)
 func ResolveConflicts(files []FileInfo, folderFilter []string) ([]ResolvedFile, []Conflict, error) {
+	return ResolveConflictsWithOptions(files, ResolveOptions{Folders: folderFilter})
+}
+
+// ResolveConflictsWithOptions resolves conflicts between files using the given options
+func ResolveConflictsWithOptions(files []FileInfo, opts ResolveOptions) ([]ResolvedFile, []Conflict, error) {
 	if len(files) == 0 {
 		return nil, nil, fmt.Errorf("no files to resolve")
 	}
 
+	folderFilter := opts.Folders
+
+	rules, err := compileRules(opts.Rules)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Group files by relative path
 	grouped := GroupFilesByRelPath(files)
 
@@ -37,6 +137,30 @@ func ResolveConflicts(files []FileInfo, folderFilter []string) ([]ResolvedFile,
 
 	// Process each group
 	for relPath, candidates := range grouped {
+		// Rendered template output is generated per project; only its template source is synced
+		if _, ok := grouped[relPath+TemplateSuffix]; ok {
+			continue
+		}
+
+		// Check if this file is in a folder that should ignore priority
+		isInFilteredFolder := isFileInFilteredFolder(relPath, folderFilter)
+		strategy := strategyFor(relPath, rules, opts.Strategy)
+
+		// Files from extended groups are layered under the group's own files
+		top := topLayer(candidates)
+
+		if strategy == StrategySharedSections {
+			// Only marked sections are synced, even when a single project has the file
+			resolvedFile, conflict, ok := resolveSharedSections(top, strategy, isInFilteredFolder)
+			if ok {
+				resolved = append(resolved, resolvedFile)
+			}
+			if conflict != nil {
+				conflicts = append(conflicts, *conflict)
+			}
+			continue
+		}
+
 		if len(candidates) == 1 {
 			// No conflict - single file
 			file := candidates[0]
@@ -48,30 +172,38 @@ func ResolveConflicts(files []FileInfo, folderFilter []string) ([]ResolvedFile,
 			})
 		} else {
 			// Conflict - multiple files with same path
-			// Check if this file is in a folder that should ignore priority
-			isInFilteredFolder := isFileInFilteredFolder(relPath, folderFilter)
-
-			var winner FileInfo
-			if isInFilteredFolder {
-				// Use modification time only (ignore priority)
-				winner = resolveConflictByModTime(candidates)
-			} else {
-				// Use standard resolution (modification time + priority)
-				winner = resolveConflict(candidates)
-			}
+			winner := pickWinner(top, isInFilteredFolder)
 
-			resolved = append(resolved, ResolvedFile{
+			resolvedFile := ResolvedFile{
 				RelPath:  winner.RelPath,
 				AbsPath:  winner.AbsPath,
 				Source:   winner.Project,
 				Priority: winner.Priority,
-			})
+			}
 
-			conflicts = append(conflicts, Conflict{
+			conflict := Conflict{
 				RelPath:    relPath,
 				Candidates: candidates,
 				Resolved:   winner,
-			})
+				Strategy:   strategy,
+			}
+
+			if strategy == StrategyJSONMerge {
+				// The winner is the base document and keeps its value on key conflicts.
+				// Lower layers are merged too, under the top layer's files.
+				content, keyConflicts, err := MergeJSONFiles(orderCandidates(candidates, winner))
+				if err != nil {
+					// Fall back to replacing the whole file
+					fmt.Fprintf(os.Stderr, "Warning: cannot merge %s, using %s: %v\n", DisplayPath(relPath), winner.Project, err)
+					conflict.Strategy = StrategyNewest
+				} else {
+					resolvedFile.Content = content
+					conflict.KeyConflicts = keyConflicts
+				}
+			}
+
+			resolved = append(resolved, resolvedFile)
+			conflicts = append(conflicts, conflict)
 		}
 	}
 
@@ -88,6 +220,24 @@ func ResolveConflicts(files []FileInfo, folderFilter []string) ([]ResolvedFile,
 	return resolved, conflicts, nil
 }
 
+// topLayer returns the candidates from the topmost (lowest numbered) layer
+func topLayer(candidates []FileInfo) []FileInfo {
+	top := candidates[0].Layer
+	for _, candidate := range candidates[1:] {
+		if candidate.Layer < top {
+			top = candidate.Layer
+		}
+	}
+
+	var result []FileInfo
+	for _, candidate := range candidates {
+		if candidate.Layer == top {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
 // resolveConflict selects the file based on modification time (newest wins)
 // If multiple files have the same timestamp (within 1 second), priority is used as fallback
 func resolveConflict(candidates []FileInfo) FileInfo {
@@ -144,6 +294,90 @@ func resolveConflictByModTime(candidates []FileInfo) FileInfo {
 	return winner
 }
 
+// pickWinner selects the winning candidate for a conflict
+func pickWinner(candidates []FileInfo, isInFilteredFolder bool) FileInfo {
+	if isInFilteredFolder {
+		// Use modification time only (ignore priority)
+		return resolveConflictByModTime(candidates)
+	}
+	// Use standard resolution (modification time + priority)
+	return resolveConflict(candidates)
+}
+
+// resolveSharedSections resolves a file whose marked sections are synced.
+// Candidates without markers are ignored; among the rest the winner provides the sections.
+// ok is false if no candidate has shared sections. A conflict is returned when more than
+// one candidate has shared sections.
+//
+// The winner is picked by whole-file modification time, not per section: if projects edit
+// different sections between pushes, every section is taken from the newest file and the
+// other edits are overwritten.
+func resolveSharedSections(candidates []FileInfo, strategy Strategy, isInFilteredFolder bool) (ResolvedFile, *Conflict, bool) {
+	var marked []FileInfo
+	sectionsByPath := make(map[string][]string)
+
+	for _, candidate := range candidates {
+		bodies, ok, err := readSharedSections(candidate.AbsPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s from %s: %v\n", DisplayPath(candidate.RelPath), candidate.Project, err)
+			continue
+		}
+		if ok {
+			marked = append(marked, candidate)
+			sectionsByPath[candidate.AbsPath] = bodies
+		}
+	}
+
+	if len(marked) == 0 {
+		return ResolvedFile{}, nil, false
+	}
+
+	winner := marked[0]
+	if len(marked) > 1 {
+		winner = pickWinner(marked, isInFilteredFolder)
+	}
+
+	resolvedFile := ResolvedFile{
+		RelPath:  winner.RelPath,
+		AbsPath:  winner.AbsPath,
+		Source:   winner.Project,
+		Priority: winner.Priority,
+		Sections: sectionsByPath[winner.AbsPath],
+	}
+
+	if len(marked) == 1 {
+		return resolvedFile, nil, true
+	}
+
+	return resolvedFile, &Conflict{
+		RelPath:    winner.RelPath,
+		Candidates: marked,
+		Resolved:   winner,
+		Strategy:   strategy,
+	}, true
+}
+
+// orderCandidates returns candidates with the winner first, followed by the rest
+// in priority order (newest first for equal priorities)
+func orderCandidates(candidates []FileInfo, winner FileInfo) []FileInfo {
+	ordered := []FileInfo{winner}
+	var rest []FileInfo
+	for _, candidate := range candidates {
+		if candidate.AbsPath != winner.AbsPath {
+			rest = append(rest, candidate)
+		}
+	}
+
+	sort.SliceStable(rest, func(i, j int) bool {
+		if rest[i].Priority != rest[j].Priority {
+			return rest[i].Priority < rest[j].Priority
+		}
+		return rest[i].ModTime.After(rest[j].ModTime)
+	})
+
+	return append(ordered, rest...)
+}
+
 // isFileInFilteredFolder checks if a file's relative path is in any of the filtered folders
 func isFileInFilteredFolder(relPath string, folderFilter []string) bool {
 	if len(folderFilter) == 0 {
@@ -171,13 +405,46 @@ func GetConflictSummary(conflicts []Conflict) string {
 
 	summary := fmt.Sprintf("%d conflict(s) resolved:\n", len(conflicts))
 	for _, conflict := range conflicts {
-		summary += fmt.Sprintf("  - %s: using %s (priority: %d)\n",
-			conflict.RelPath,
+		summary += "  " + FormatConflict(conflict)
+	}
+
+	return summary
+}
+
+// FormatConflict returns a one-line description of how a conflict was resolved,
+// followed by one line per key-level conflict for merged files
+func FormatConflict(conflict Conflict) string {
+	switch conflict.Strategy {
+	case StrategyJSONMerge:
+		// Handled below
+	case StrategySharedSections:
+		return fmt.Sprintf("- %s: shared sections from %s (priority: %d)\n",
+			DisplayPath(conflict.RelPath),
+			conflict.Resolved.Project,
+			conflict.Resolved.Priority)
+	default:
+		return fmt.Sprintf("- %s: using %s (priority: %d)\n",
+			DisplayPath(conflict.RelPath),
 			conflict.Resolved.Project,
 			conflict.Resolved.Priority)
 	}
 
-	return summary
+	projects := make([]string, 0, len(conflict.Candidates))
+	for _, candidate := range orderCandidates(conflict.Candidates, conflict.Resolved) {
+		projects = append(projects, candidate.Project)
+	}
+
+	line := fmt.Sprintf("- %s: merged from %s", DisplayPath(conflict.RelPath), strings.Join(projects, ", "))
+	if len(conflict.KeyConflicts) > 0 {
+		line += fmt.Sprintf(" (%d key conflict(s))", len(conflict.KeyConflicts))
+	}
+	line += "\n"
+
+	for _, kc := range conflict.KeyConflicts {
+		line += fmt.Sprintf("    %s: using %s (differs in %s)\n", kc.Key, kc.Source, strings.Join(kc.Overridden, ", "))
+	}
+
+	return line
 }
 
 // GetResolvedSummary returns a summary of resolved files
@@ -465,3 +465,53 @@ func TestIsFileInFilteredFolder(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveConflicts_Layers(t *testing.T) {
+	now := time.Now()
+	files := []FileInfo{
+		{RelPath: "commands/a.md", AbsPath: "/app/commands/a.md", Project: "app", Priority: 1, ModTime: now.Add(-1 * time.Hour)},
+		{RelPath: "commands/a.md", AbsPath: "/base/commands/a.md", Project: "base", Priority: 2, ModTime: now, Layer: 1},
+		{RelPath: "commands/b.md", AbsPath: "/base/commands/b.md", Project: "base", Priority: 2, ModTime: now, Layer: 1},
+	}
+
+	resolved, _, err := ResolveConflicts(files, nil)
+	if err != nil {
+		t.Fatalf("ResolveConflicts failed: %v", err)
+	}
+
+	if len(resolved) != 2 {
+		t.Fatalf("Expected 2 resolved files, got %d", len(resolved))
+	}
+	// The group's own file wins even though the inherited one is newer
+	if resolved[0].RelPath != "commands/a.md" || resolved[0].Source != "app" {
+		t.Errorf("Expected commands/a.md from app, got %s from %s", resolved[0].RelPath, resolved[0].Source)
+	}
+	// Inherited files fill in paths the group does not have
+	if resolved[1].RelPath != "commands/b.md" || resolved[1].Source != "base" {
+		t.Errorf("Expected commands/b.md from base, got %s from %s", resolved[1].RelPath, resolved[1].Source)
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Strategy
+		wantErr bool
+	}{
+		{"", StrategyNewest, false},
+		{"newest", StrategyNewest, false},
+		{"json-merge", StrategyJSONMerge, false},
+		{"oldest", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseStrategy(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseStrategy(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseStrategy(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package syncer
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/yugo-ibuki/dot-claude-sync/config"
+	"github.com/yugo-ibuki/dot-claude-sync/utils"
+)
+
+// RootFilePrefix prefixes the relative path of files collected from the project root, so
+// that "../CLAUDE.md" is the project's root CLAUDE.md whatever the synced directory is.
+// Messages show such paths with DisplayPath.
+const RootFilePrefix = "../"
+
+// rootDisplayPrefix stands for the project root in messages
+const rootDisplayPrefix = "<root>/"
+
+// IsRootFile reports whether relPath refers to a file in the project root
+func IsRootFile(relPath string) bool {
+	return strings.HasPrefix(relPath, RootFilePrefix)
+}
+
+// DisplayPath returns relPath as shown in messages: files in the project root as
+// "<root>/CLAUDE.md", other files relative to the synced directory
+func DisplayPath(relPath string) string {
+	if IsRootFile(relPath) {
+		return rootDisplayPrefix + strings.TrimPrefix(relPath, RootFilePrefix)
+	}
+	return relPath
+}
+
+// NormalizeRootFiles validates root file paths relative to the project root and returns
+// them in slash-separated form. Paths must stay inside the project and outside dir, the
+// synced directory (.claude when empty).
+func NormalizeRootFiles(rootFiles []string, dir string) ([]string, error) {
+	if dir == "" {
+		dir = config.DefaultDir
+	}
+	synced := path.Clean(filepath.ToSlash(dir))
+
+	var normalized []string
+	seen := make(map[string]bool)
+
+	for _, rootFile := range rootFiles {
+		slashed := filepath.ToSlash(strings.TrimSpace(rootFile))
+		if slashed == "" || path.IsAbs(slashed) || filepath.IsAbs(rootFile) {
+			return nil, fmt.Errorf("invalid root file '%s': must be relative to the project root", rootFile)
+		}
+
+		cleaned := path.Clean(slashed)
+		if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return nil, fmt.Errorf("invalid root file '%s': must be inside the project", rootFile)
+		}
+		if cleaned == synced || strings.HasPrefix(cleaned, synced+"/") {
+			return nil, fmt.Errorf("invalid root file '%s': files in %s are synced already", rootFile, dir)
+		}
+
+		if !seen[cleaned] {
+			seen[cleaned] = true
+			normalized = append(normalized, cleaned)
+		}
+	}
+
+	return normalized, nil
+}
+
+// projectRoot returns the root directory of a project whose synced directory is dir
+func projectRoot(project config.ProjectPath, dir string) string {
+	if project.Root != "" {
+		return utils.ExpandHome(project.Root)
+	}
+	return filepath.Dir(dir)
+}
+
+// SyncedDirName returns the synced directory of a project relative to its root
+// (e.g., .claude or .github/prompts), for messages
+func SyncedDirName(project config.ProjectPath) string {
+	dir := utils.ExpandHome(project.Path)
+	if rel, err := filepath.Rel(projectRoot(project, dir), dir); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return filepath.Base(dir)
+}
+
+// destPath returns where relPath is written in a project whose synced directory is dir
+func destPath(project config.ProjectPath, dir, relPath string) string {
+	if IsRootFile(relPath) {
+		return filepath.Join(projectRoot(project, dir), filepath.FromSlash(strings.TrimPrefix(relPath, RootFilePrefix)))
+	}
+	return filepath.Join(dir, relPath)
+}
@@ -0,0 +1,162 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yugo-ibuki/dot-claude-sync/config"
+)
+
+func TestNormalizeRootFiles(t *testing.T) {
+	got, err := NormalizeRootFiles([]string{"CLAUDE.md", "./.mcp.json", "docs/AGENTS.md", "CLAUDE.md"}, "")
+	if err != nil {
+		t.Fatalf("NormalizeRootFiles failed: %v", err)
+	}
+	expected := []string{"CLAUDE.md", ".mcp.json", "docs/AGENTS.md"}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	}
+
+	for _, invalid := range []string{"", "/etc/passwd", "../other/CLAUDE.md", ".claude/CLAUDE.md", "."} {
+		if _, err := NormalizeRootFiles([]string{invalid}, ""); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+
+	// Only the group's synced directory is rejected
+	if _, err := NormalizeRootFiles([]string{".claude/CLAUDE.md"}, ".cursor"); err != nil {
+		t.Errorf("Expected .claude to be allowed when syncing .cursor: %v", err)
+	}
+	if _, err := NormalizeRootFiles([]string{".cursor/rules.md"}, ".cursor"); err == nil {
+		t.Error("Expected error for a file in the synced directory")
+	}
+}
+
+func TestSyncRootFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	root1 := filepath.Join(tmpDir, "main")
+	root2 := filepath.Join(tmpDir, "feature")
+	for _, root := range []string{root1, root2} {
+		if err := os.MkdirAll(filepath.Join(root, ".claude"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeFile := func(path, content string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now := time.Now()
+	writeFile(filepath.Join(root1, "CLAUDE.md"), "old root\n", now.Add(-time.Hour))
+	writeFile(filepath.Join(root2, "CLAUDE.md"), "new root\n", now)
+	writeFile(filepath.Join(root1, ".mcp.json"), "{}\n", now)
+	writeFile(filepath.Join(root1, ".claude", "CLAUDE.md"), "inside .claude\n", now)
+
+	projects := []config.ProjectPath{
+		{Alias: "main", Path: filepath.Join(root1, ".claude"), Priority: 1},
+		{Alias: "feature", Path: filepath.Join(root2, ".claude"), Priority: 2},
+	}
+
+	files, _, err := CollectFilesWithReport(projects, CollectOptions{RootFiles: []string{"CLAUDE.md", ".mcp.json"}})
+	if err != nil {
+		t.Fatalf("CollectFilesWithReport failed: %v", err)
+	}
+
+	resolved, conflicts, err := ResolveConflicts(files, nil)
+	if err != nil {
+		t.Fatalf("ResolveConflicts failed: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].RelPath != "../CLAUDE.md" || conflicts[0].Resolved.Project != "feature" {
+		t.Fatalf("Expected root CLAUDE.md conflict won by feature, got %+v", conflicts)
+	}
+
+	if _, err := SyncFiles(resolved, projects, false, false, true); err != nil {
+		t.Fatalf("SyncFiles failed: %v", err)
+	}
+
+	expected := map[string]string{
+		filepath.Join(root1, "CLAUDE.md"):            "new root\n",
+		filepath.Join(root2, "CLAUDE.md"):            "new root\n",
+		filepath.Join(root2, ".mcp.json"):            "{}\n",
+		filepath.Join(root2, ".claude", "CLAUDE.md"): "inside .claude\n",
+	}
+	for path, want := range expected {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s: expected %q, got %q", path, want, string(got))
+		}
+	}
+}
+
+func TestDestPath(t *testing.T) {
+	project := config.ProjectPath{Alias: "p", Path: "/work/p/.github/prompts", Root: "/work/p"}
+
+	tests := []struct {
+		relPath string
+		want    string
+	}{
+		{"review.prompt.md", "/work/p/.github/prompts/review.prompt.md"},
+		{"../AGENTS.md", "/work/p/AGENTS.md"},
+		{"../docs/setup.md", "/work/p/docs/setup.md"},
+	}
+
+	for _, tt := range tests {
+		got := destPath(project, project.Path, tt.relPath)
+		if got != filepath.FromSlash(tt.want) {
+			t.Errorf("destPath(%q) = %q, want %q", tt.relPath, got, tt.want)
+		}
+	}
+
+	// Without Root the parent of the synced directory is the project root
+	legacy := config.ProjectPath{Alias: "p", Path: "/work/p/.claude"}
+	if got := destPath(legacy, legacy.Path, "../CLAUDE.md"); got != filepath.FromSlash("/work/p/CLAUDE.md") {
+		t.Errorf("Unexpected root file path: %s", got)
+	}
+}
+
+func TestSyncedDirName(t *testing.T) {
+	tests := []struct {
+		project config.ProjectPath
+		want    string
+	}{
+		{config.ProjectPath{Path: "/work/p/.claude"}, ".claude"},
+		{config.ProjectPath{Path: "/work/p/.github/prompts", Root: "/work/p"}, filepath.FromSlash(".github/prompts")},
+	}
+
+	for _, tt := range tests {
+		if got := SyncedDirName(tt.project); got != tt.want {
+			t.Errorf("SyncedDirName(%s) = %q, want %q", tt.project.Path, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayPath(t *testing.T) {
+	tests := map[string]string{
+		"../CLAUDE.md":       "<root>/CLAUDE.md",
+		"../docs/AGENTS.md":  "<root>/docs/AGENTS.md",
+		"commands/review.md": "commands/review.md",
+	}
+
+	for relPath, want := range tests {
+		if got := DisplayPath(relPath); got != want {
+			t.Errorf("DisplayPath(%q) = %q, want %q", relPath, got, want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package syncer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SecretRule detects a kind of credential in file content
+type SecretRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// BuiltinSecretRules are always applied when scanning for secrets
+var BuiltinSecretRules = []SecretRule{
+	{Name: "private key", Pattern: regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |ENCRYPTED |PGP )?PRIVATE KEY(?: BLOCK)?-----`)},
+	{Name: "AWS access key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "GitHub token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{Name: "Anthropic API key", Pattern: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+	{Name: "OpenAI API key", Pattern: regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9]{32,}\b`)},
+	{Name: "Slack token", Pattern: regexp.MustCompile(`\bxox[baprs]-[A-Za-z0-9-]{10,}`)},
+	{Name: "Stripe secret key", Pattern: regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{20,}\b`)},
+	{Name: "Google API key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{Name: "credential assignment", Pattern: regexp.MustCompile(`^\s*(?:export\s+)?[A-Z0-9_]*(?:SECRET|TOKEN|PASSWORD|PASSWD|API_KEY|APIKEY|ACCESS_KEY|PRIVATE_KEY)[A-Z0-9_]*\s*=\s*['"]?[^\s'"$]{8,}`)},
+}
+
+// SecretFinding represents a potential secret found in a resolved file
+type SecretFinding struct {
+	RelPath string // Relative path of the file
+	Source  string // Project the file would be distributed from
+	Line    int    // 1-based line number
+	Rule    string // Name of the matching rule
+	Match   string // Redacted matching text
+}
+
+// CompileSecretPatterns compiles custom secret patterns into rules
+func CompileSecretPatterns(patterns []string) ([]SecretRule, error) {
+	rules := make([]SecretRule, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret pattern '%s': %w", pattern, err)
+		}
+		rules = append(rules, SecretRule{Name: "custom: " + pattern, Pattern: re})
+	}
+	return rules, nil
+}
+
+// ScanSecrets scans resolved files for potential secrets using the built-in rules
+// followed by the given custom rules. Binary files are skipped.
+func ScanSecrets(resolved []ResolvedFile, custom []SecretRule) ([]SecretFinding, error) {
+	rules := append(append([]SecretRule{}, BuiltinSecretRules...), custom...)
+
+	var findings []SecretFinding
+	for _, file := range resolved {
+		content, err := scannedContent(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", DisplayPath(file.RelPath), err)
+		}
+		if bytes.IndexByte(content, 0) >= 0 {
+			continue // Binary file
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+		lineNum := 0
+		for scanner.Scan() {
+			lineNum++
+			line := scanner.Text()
+			for _, rule := range rules {
+				match := rule.Pattern.FindString(line)
+				if match == "" {
+					continue
+				}
+				findings = append(findings, SecretFinding{
+					RelPath: file.RelPath,
+					Source:  file.Source,
+					Line:    lineNum,
+					Rule:    rule.Name,
+					Match:   redact(strings.TrimSpace(match)),
+				})
+				break // One finding per line is enough
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", DisplayPath(file.RelPath), err)
+		}
+	}
+
+	return findings, nil
+}
+
+// scannedContent returns the content a resolved file distributes
+func scannedContent(file ResolvedFile) ([]byte, error) {
+	switch {
+	case file.Sections != nil:
+		return []byte(strings.Join(file.Sections, "\n")), nil
+	case file.Content != nil:
+		return file.Content, nil
+	default:
+		return os.ReadFile(file.AbsPath)
+	}
+}
+
+// redact hides all but the first few characters of a match
+func redact(match string) string {
+	const visible = 6
+	if len(match) <= visible {
+		return strings.Repeat("*", len(match))
+	}
+	return match[:visible] + strings.Repeat("*", 8)
+}
+
+// FormatSecretFinding returns a one-line description of a finding
+func FormatSecretFinding(finding SecretFinding) string {
+	return fmt.Sprintf("- %s:%d (from %s): %s [%s]\n", DisplayPath(finding.RelPath), finding.Line, finding.Source, finding.Rule, finding.Match)
+}
@@ -0,0 +1,110 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanSecrets(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantRule string // Empty means no finding
+		wantLine int
+	}{
+		{
+			name:     "AWS access key",
+			content:  "# Notes\naws key: AKIA" + "IOSFODNN7EXAMPLE\n",
+			wantRule: "AWS access key",
+			wantLine: 2,
+		},
+		{
+			name:     "GitHub token",
+			content:  "token ghp_" + strings.Repeat("a1B2", 9) + " here",
+			wantRule: "GitHub token",
+			wantLine: 1,
+		},
+		{
+			name:     "private key",
+			content:  "-----BEGIN OPENSSH " + "PRIVATE KEY-----\nabc\n",
+			wantRule: "private key",
+			wantLine: 1,
+		},
+		{
+			name:     "env assignment",
+			content:  "NODE_ENV=production\nexport DATABASE_PASSWORD='hunter2hunter2'\n",
+			wantRule: "credential assignment",
+			wantLine: 2,
+		},
+		{
+			name:    "env reference is not a secret",
+			content: "API_KEY=$API_KEY\nGITHUB_TOKEN=${GITHUB_TOKEN}\n",
+		},
+		{
+			name:    "prose",
+			content: "Set max_tokens = 10000000 and keep the token secret.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := ResolvedFile{RelPath: "CLAUDE.md", Source: "p1", Content: []byte(tt.content)}
+			findings, err := ScanSecrets([]ResolvedFile{file}, nil)
+			if err != nil {
+				t.Fatalf("ScanSecrets failed: %v", err)
+			}
+
+			if tt.wantRule == "" {
+				if len(findings) != 0 {
+					t.Errorf("Expected no findings, got %+v", findings)
+				}
+				return
+			}
+
+			if len(findings) != 1 {
+				t.Fatalf("Expected 1 finding, got %+v", findings)
+			}
+			if findings[0].Rule != tt.wantRule || findings[0].Line != tt.wantLine {
+				t.Errorf("Expected %s on line %d, got %+v", tt.wantRule, tt.wantLine, findings[0])
+			}
+			if strings.Contains(tt.content, findings[0].Match) {
+				t.Errorf("Expected match to be redacted, got %q", findings[0].Match)
+			}
+		})
+	}
+}
+
+func TestScanSecrets_CustomPatternsAndFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	text := filepath.Join(tmpDir, "prompt.md")
+	if err := os.WriteFile(text, []byte("internal id: ACME-1234-5678\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	binary := filepath.Join(tmpDir, "image.png")
+	if err := os.WriteFile(binary, []byte("ACME-1234-5678\x00\x01"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := CompileSecretPatterns([]string{`ACME-\d{4}-\d{4}`})
+	if err != nil {
+		t.Fatalf("CompileSecretPatterns failed: %v", err)
+	}
+
+	findings, err := ScanSecrets([]ResolvedFile{
+		{RelPath: "prompt.md", AbsPath: text, Source: "p1"},
+		{RelPath: "image.png", AbsPath: binary, Source: "p1"},
+	}, rules)
+	if err != nil {
+		t.Fatalf("ScanSecrets failed: %v", err)
+	}
+	if len(findings) != 1 || findings[0].RelPath != "prompt.md" || !strings.HasPrefix(findings[0].Rule, "custom:") {
+		t.Errorf("Expected one custom finding in prompt.md, got %+v", findings)
+	}
+
+	if _, err := CompileSecretPatterns([]string{"("}); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,129 @@
+package syncer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+)
+
+const (
+	// SharedBeginMarker starts a section that is synced across the group
+	SharedBeginMarker = "<!-- dcs:shared begin -->"
+	// SharedEndMarker ends a section that is synced across the group
+	SharedEndMarker = "<!-- dcs:shared end -->"
+)
+
+// sharedSection holds the byte offsets of a section body, excluding the marker lines
+type sharedSection struct {
+	start int // Offset of the first byte after the begin marker line
+	end   int // Offset of the first byte of the end marker line
+}
+
+// findSharedSections locates all marker-delimited sections in content
+func findSharedSections(content []byte) ([]sharedSection, error) {
+	var sections []sharedSection
+	open := -1
+	lineNum := 0
+
+	for offset := 0; offset < len(content); {
+		lineNum++
+		lineEnd := bytes.IndexByte(content[offset:], '\n')
+		next := len(content)
+		if lineEnd >= 0 {
+			next = offset + lineEnd + 1
+		}
+		line := string(bytes.TrimSpace(content[offset:next]))
+
+		switch line {
+		case SharedBeginMarker:
+			if open >= 0 {
+				return nil, fmt.Errorf("line %d: nested %s", lineNum, SharedBeginMarker)
+			}
+			open = next
+		case SharedEndMarker:
+			if open < 0 {
+				return nil, fmt.Errorf("line %d: %s without matching begin marker", lineNum, SharedEndMarker)
+			}
+			sections = append(sections, sharedSection{start: open, end: offset})
+			open = -1
+		}
+
+		offset = next
+	}
+
+	if open >= 0 {
+		return nil, fmt.Errorf("unterminated %s", SharedBeginMarker)
+	}
+
+	return sections, nil
+}
+
+// ExtractSharedSections returns the bodies of all shared sections in content
+func ExtractSharedSections(content []byte) ([]string, error) {
+	sections, err := findSharedSections(content)
+	if err != nil {
+		return nil, err
+	}
+
+	bodies := make([]string, 0, len(sections))
+	for _, section := range sections {
+		bodies = append(bodies, string(content[section.start:section.end]))
+	}
+
+	return bodies, nil
+}
+
+// ApplySharedSections replaces the shared sections in content with the given bodies, in order.
+// Content outside the markers is left untouched. Bodies without a matching section in
+// content are appended to the end as new marked sections.
+func ApplySharedSections(content []byte, bodies []string) ([]byte, error) {
+	sections, err := findSharedSections(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	last := 0
+	for i, section := range sections {
+		if i >= len(bodies) {
+			break
+		}
+		buf.Write(content[last:section.start])
+		buf.WriteString(bodies[i])
+		last = section.end
+	}
+	buf.Write(content[last:])
+
+	for i := len(sections); i < len(bodies); i++ {
+		if buf.Len() > 0 {
+			if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+				buf.WriteByte('\n')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(SharedBeginMarker + "\n")
+		buf.WriteString(bodies[i])
+		if bodies[i] != "" && bodies[i][len(bodies[i])-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(SharedEndMarker + "\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// readSharedSections reads a file and extracts its shared sections.
+// ok is false if the file has no shared sections.
+func readSharedSections(path string) (bodies []string, ok bool, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	bodies, err = ExtractSharedSections(content)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return bodies, len(bodies) > 0, nil
+}
@@ -0,0 +1,237 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yugo-ibuki/dot-claude-sync/config"
+)
+
+func TestExtractSharedSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "no markers",
+			content: "# Project\nlocal notes\n",
+			want:    []string{},
+		},
+		{
+			name:    "single section",
+			content: "# Project\n<!-- dcs:shared begin -->\nshared rule\n<!-- dcs:shared end -->\nlocal\n",
+			want:    []string{"shared rule\n"},
+		},
+		{
+			name:    "multiple sections with indented markers",
+			content: "  <!-- dcs:shared begin -->\na\n  <!-- dcs:shared end -->\nx\n<!-- dcs:shared begin -->\n<!-- dcs:shared end -->\n",
+			want:    []string{"a\n", ""},
+		},
+		{
+			name:    "unterminated section",
+			content: "<!-- dcs:shared begin -->\na\n",
+			wantErr: true,
+		},
+		{
+			name:    "nested begin",
+			content: "<!-- dcs:shared begin -->\n<!-- dcs:shared begin -->\n<!-- dcs:shared end -->\n",
+			wantErr: true,
+		},
+		{
+			name:    "end without begin",
+			content: "a\n<!-- dcs:shared end -->\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractSharedSections([]byte(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractSharedSections() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %d sections, got %d: %q", len(tt.want), len(got), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Section %d: expected %q, got %q", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestApplySharedSections(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		bodies  []string
+		want    string
+	}{
+		{
+			name:    "replace existing section",
+			content: "# B\n<!-- dcs:shared begin -->\nold\n<!-- dcs:shared end -->\nB only\n",
+			bodies:  []string{"new\n"},
+			want:    "# B\n<!-- dcs:shared begin -->\nnew\n<!-- dcs:shared end -->\nB only\n",
+		},
+		{
+			name:    "append missing section",
+			content: "# B\nB only",
+			bodies:  []string{"new\n"},
+			want:    "# B\nB only\n\n<!-- dcs:shared begin -->\nnew\n<!-- dcs:shared end -->\n",
+		},
+		{
+			name:    "create from empty file",
+			content: "",
+			bodies:  []string{"new"},
+			want:    "<!-- dcs:shared begin -->\nnew\n<!-- dcs:shared end -->\n",
+		},
+		{
+			name:    "extra destination sections are left alone",
+			content: "<!-- dcs:shared begin -->\n1\n<!-- dcs:shared end -->\n<!-- dcs:shared begin -->\n2\n<!-- dcs:shared end -->\n",
+			bodies:  []string{"one\n"},
+			want:    "<!-- dcs:shared begin -->\none\n<!-- dcs:shared end -->\n<!-- dcs:shared begin -->\n2\n<!-- dcs:shared end -->\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplySharedSections([]byte(tt.content), tt.bodies)
+			if err != nil {
+				t.Fatalf("ApplySharedSections failed: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Expected:\n%q\ngot:\n%q", tt.want, string(got))
+			}
+		})
+	}
+}
+
+func TestSyncSharedSections(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	project1 := filepath.Join(tmpDir, "project1", ".claude")
+	project2 := filepath.Join(tmpDir, "project2", ".claude")
+	project3 := filepath.Join(tmpDir, "project3", ".claude")
+	for _, dir := range []string{project1, project2, project3} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content1 := "# Project 1\n<!-- dcs:shared begin -->\nUse tabs.\n<!-- dcs:shared end -->\nProject 1 notes\n"
+	content2 := "# Project 2\n<!-- dcs:shared begin -->\nUse spaces.\n<!-- dcs:shared end -->\nProject 2 notes\n"
+	if err := os.WriteFile(filepath.Join(project1, "CLAUDE.md"), []byte(content1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project2, "CLAUDE.md"), []byte(content2), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// project1 is newer, so its shared section wins
+	past := time.Now().Add(-1 * time.Hour)
+	if err := os.Chtimes(filepath.Join(project2, "CLAUDE.md"), past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	projects := []config.ProjectPath{
+		{Alias: "project1", Path: project1, Priority: 1},
+		{Alias: "project2", Path: project2, Priority: 2},
+		{Alias: "project3", Path: project3, Priority: 3},
+	}
+
+	files, err := CollectFiles(projects, nil)
+	if err != nil {
+		t.Fatalf("CollectFiles failed: %v", err)
+	}
+
+	resolved, conflicts, err := ResolveConflictsWithOptions(files, ResolveOptions{
+		Rules: []Rule{{Pattern: "CLAUDE.md", Strategy: StrategySharedSections}},
+	})
+	if err != nil {
+		t.Fatalf("ResolveConflictsWithOptions failed: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Resolved.Project != "project1" {
+		t.Fatalf("Expected project1 to provide shared sections, got %+v", conflicts)
+	}
+
+	if _, err := SyncFiles(resolved, projects, false, false, true); err != nil {
+		t.Fatalf("SyncFiles failed: %v", err)
+	}
+
+	expected := map[string]string{
+		project1: content1,
+		project2: "# Project 2\n<!-- dcs:shared begin -->\nUse tabs.\n<!-- dcs:shared end -->\nProject 2 notes\n",
+		project3: "<!-- dcs:shared begin -->\nUse tabs.\n<!-- dcs:shared end -->\n",
+	}
+	for dir, want := range expected {
+		got, err := os.ReadFile(filepath.Join(dir, "CLAUDE.md"))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", dir, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s: expected:\n%q\ngot:\n%q", dir, want, string(got))
+		}
+	}
+}
+
+func TestResolveSharedSections_NoMarkers(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "CLAUDE.md")
+	if err := os.WriteFile(path, []byte("project specific only\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files := []FileInfo{{RelPath: "CLAUDE.md", AbsPath: path, Project: "p1", Priority: 1}}
+	resolved, conflicts, err := ResolveConflictsWithOptions(files, ResolveOptions{Strategy: StrategySharedSections})
+	if err != nil {
+		t.Fatalf("ResolveConflictsWithOptions failed: %v", err)
+	}
+	if len(resolved) != 0 || len(conflicts) != 0 {
+		t.Errorf("Expected file without markers not to be synced, got %+v", resolved)
+	}
+}
+
+func TestResolveSharedSections_WholeFileWinner(t *testing.T) {
+	tmpDir := t.TempDir()
+	older := filepath.Join(tmpDir, "older.md")
+	newer := filepath.Join(tmpDir, "newer.md")
+	// older edited the first section, newer the second
+	olderContent := SharedBeginMarker + "\nedited one\n" + SharedEndMarker + "\n" + SharedBeginMarker + "\ntwo\n" + SharedEndMarker + "\n"
+	newerContent := SharedBeginMarker + "\none\n" + SharedEndMarker + "\n" + SharedBeginMarker + "\nedited two\n" + SharedEndMarker + "\n"
+	if err := os.WriteFile(older, []byte(olderContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newer, []byte(newerContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	files := []FileInfo{
+		{RelPath: "CLAUDE.md", AbsPath: older, Project: "p1", Priority: 1, ModTime: now.Add(-time.Hour)},
+		{RelPath: "CLAUDE.md", AbsPath: newer, Project: "p2", Priority: 2, ModTime: now},
+	}
+	resolved, conflicts, err := ResolveConflictsWithOptions(files, ResolveOptions{Strategy: StrategySharedSections})
+	if err != nil {
+		t.Fatalf("ResolveConflictsWithOptions failed: %v", err)
+	}
+
+	// All sections come from the newest file, so the older file's edit is not kept
+	if len(resolved) != 1 || resolved[0].Source != "p2" {
+		t.Fatalf("Expected the newest file to win, got %+v", resolved)
+	}
+	if want := []string{"one\n", "edited two\n"}; len(resolved[0].Sections) != 2 ||
+		resolved[0].Sections[0] != want[0] || resolved[0].Sections[1] != want[1] {
+		t.Errorf("Expected sections %q, got %q", want, resolved[0].Sections)
+	}
+	if len(conflicts) != 1 {
+		t.Errorf("Expected the overwritten edit to be reported as a conflict, got %d", len(conflicts))
+	}
+}
@@ -1,9 +1,11 @@
 package syncer
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
-	"path/filepath"
 
 	"github.com/yugo-ibuki/dot-claude-sync/config"
 	"github.com/yugo-ibuki/dot-claude-sync/utils"
@@ -14,6 +16,7 @@ type SyncResult struct {
 	Project     string  // Project alias
 	NewFiles    int     // Number of new files added
 	Overwritten int     // Number of existing files overwritten
+	Overlaid    int     // Number of files overridden locally by the project's overlay
 	Failed      int     // Number of failed operations
 	Errors      []error // List of errors encountered
 	Skipped     bool    // Whether the project was skipped
@@ -28,29 +31,113 @@ type OverwriteInfo struct {
 	ContentDiff   bool   // Whether the content is different
 }
 
+// SyncOptions controls how resolved files are distributed
+type SyncOptions struct {
+	DryRun  bool              // Simulate without writing files
+	Verbose bool              // Print each file operation
+	Force   bool              // Skip the overwrite confirmation prompt
+	Vars    map[string]string // Variables available to templates as .Vars
+	Overlay string            // Project-local overlay directory relative to .claude; empty disables overlays
+}
+
 // SyncFiles distributes resolved files to all projects
 func SyncFiles(resolved []ResolvedFile, projects []config.ProjectPath, dryRun bool, verbose bool, force bool) ([]SyncResult, error) {
+	return SyncFilesWithOptions(resolved, projects, SyncOptions{DryRun: dryRun, Verbose: verbose, Force: force})
+}
+
+// SyncFilesWithOptions distributes resolved files to all projects using the given sync options
+func SyncFilesWithOptions(resolved []ResolvedFile, projects []config.ProjectPath, opts SyncOptions) ([]SyncResult, error) {
+	dryRun, force := opts.DryRun, opts.Force
+
 	if len(resolved) == 0 {
 		return nil, fmt.Errorf("no files to sync")
 	}
 
+	if opts.Overlay != "" {
+		overlay, err := NormalizeOverlayDir(opts.Overlay)
+		if err != nil {
+			return nil, err
+		}
+		opts.Overlay = overlay
+	}
+
 	// Collect files that would be overwritten
+	overwriteInfo, err := findOverwrites(resolved, projects, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Show warning and ask for confirmation if overwrites would occur
+	if len(overwriteInfo) > 0 && !dryRun && !force {
+		fmt.Println("\n⚠️  Warning: The following files will be overwritten:")
+		fmt.Println()
+
+		// Group by destination project, in project order
+		byDestProject := make(map[string][]OverwriteInfo)
+		for _, info := range overwriteInfo {
+			byDestProject[info.DestProject] = append(byDestProject[info.DestProject], info)
+		}
+
+		for _, project := range projects {
+			infos, ok := byDestProject[project.Alias]
+			if !ok {
+				continue
+			}
+			fmt.Printf("  %s:\n", project.Alias)
+			for _, info := range infos {
+				// Color the file path in red and the source in yellow
+				fmt.Printf("    - \033[31m%s\033[0m (from \033[33m%s\033[0m)\n", DisplayPath(info.RelPath), info.SourceProject)
+			}
+		}
+
+		fmt.Println()
+		if !utils.Confirm("Do you want to continue?") {
+			return nil, fmt.Errorf("sync cancelled by user")
+		}
+		fmt.Println()
+	}
+
+	var results []SyncResult
+
+	for _, project := range projects {
+		result := syncToProject(resolved, project, opts)
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// findOverwrites returns the existing files of lower priority projects whose content
+// would change, including the rendered output of templates
+func findOverwrites(resolved []ResolvedFile, projects []config.ProjectPath, opts SyncOptions) ([]OverwriteInfo, error) {
 	var overwriteInfo []OverwriteInfo
 	for _, project := range projects {
-		claudeDir := expandPath(project.Path)
-		if !utils.FileExists(claudeDir) {
+		claudeDir := utils.ExpandHome(project.Path)
+		if !utils.FileExists(claudeDir) || project.Layer > 0 {
 			continue
 		}
 
+		mappings, err := projectMappings(project)
+		if err != nil {
+			return nil, err
+		}
+
+		// Template data is built on first use since it needs the project's git state
+		var templateData *TemplateData
+
 		for _, file := range resolved {
-			dstPath := filepath.Join(claudeDir, file.RelPath)
+			relPath := toLocal(file.RelPath, mappings)
+			dstPath := destPath(project, claudeDir, relPath)
+			if _, ok := overlayFile(claudeDir, opts.Overlay, relPath); ok {
+				continue // Overlaid paths are never overwritten by synced content
+			}
 			if utils.FileExists(dstPath) {
 				// Only show if:
 				// 1. Destination project has lower priority (higher number) than source
 				// 2. Content is actually different
 				if project.Priority > file.Priority {
 					// Check if content is different
-					srcHash, err := utils.FileHash(file.AbsPath)
+					srcHash, err := resolvedHash(file, dstPath)
 					if err != nil {
 						continue // Skip if can't read source
 					}
@@ -64,80 +151,129 @@ func SyncFiles(resolved []ResolvedFile, projects []config.ProjectPath, dryRun bo
 						overwriteInfo = append(overwriteInfo, OverwriteInfo{
 							DestProject:   project.Alias,
 							SourceProject: file.Source,
-							RelPath:       file.RelPath,
+							RelPath:       relPath,
 							ContentDiff:   true,
 						})
 					}
 				}
 			}
-		}
-	}
-
-	// Show warning and ask for confirmation if overwrites would occur
-	if len(overwriteInfo) > 0 && !dryRun && !force {
-		fmt.Println("\n⚠️  Warning: The following files will be overwritten:")
-		fmt.Println()
 
-		// Group by destination project
-		byDestProject := make(map[string][]OverwriteInfo)
-		for _, info := range overwriteInfo {
-			byDestProject[info.DestProject] = append(byDestProject[info.DestProject], info)
-		}
-
-		for destProject, infos := range byDestProject {
-			fmt.Printf("  %s:\n", destProject)
-			for _, info := range infos {
-				// Color the file path in red and the source in yellow
-				fmt.Printf("    - \033[31m%s\033[0m (from \033[33m%s\033[0m)\n", info.RelPath, info.SourceProject)
+			// Rendered output differs per project, so it is compared with what would be rendered here
+			if !IsTemplate(relPath) || project.Priority <= file.Priority {
+				continue
+			}
+			outputRelPath := TemplateOutput(relPath)
+			if _, ok := overlayFile(claudeDir, opts.Overlay, outputRelPath); ok {
+				continue
+			}
+			existing, err := os.ReadFile(TemplateOutput(dstPath))
+			if err != nil {
+				continue // Not written yet, or unreadable
+			}
+			if templateData == nil {
+				data := newTemplateData(project, claudeDir, opts.Vars)
+				templateData = &data
+			}
+			rendered, err := renderTemplateFile(file, dstPath, *templateData)
+			if err != nil {
+				continue // Reported when syncing
+			}
+			if !bytes.Equal(rendered, existing) {
+				overwriteInfo = append(overwriteInfo, OverwriteInfo{
+					DestProject:   project.Alias,
+					SourceProject: file.Source,
+					RelPath:       outputRelPath,
+					ContentDiff:   true,
+				})
 			}
 		}
-
-		fmt.Println()
-		if !utils.Confirm("Do you want to continue?") {
-			return nil, fmt.Errorf("sync cancelled by user")
-		}
-		fmt.Println()
-	}
-
-	var results []SyncResult
-
-	for _, project := range projects {
-		result := syncToProject(resolved, project, dryRun, verbose)
-		results = append(results, result)
 	}
 
-	return results, nil
+	return overwriteInfo, nil
 }
 
 // syncToProject syncs files to a single project
-func syncToProject(resolved []ResolvedFile, project config.ProjectPath, dryRun bool, verbose bool) SyncResult {
+func syncToProject(resolved []ResolvedFile, project config.ProjectPath, opts SyncOptions) SyncResult {
+	dryRun, verbose := opts.DryRun, opts.Verbose
 	result := SyncResult{
 		Project: project.Alias,
 		Errors:  []error{},
 	}
 
-	claudeDir := expandPath(project.Path)
+	// Projects inherited from extended groups are only read from
+	if project.Layer > 0 {
+		result.Skipped = true
+		result.SkipReason = "inherited from an extended group (push that group to update it)"
+		return result
+	}
+
+	claudeDir := utils.ExpandHome(project.Path)
 
-	// Check if .claude directory exists
+	// Check if the synced directory exists
 	if !utils.FileExists(claudeDir) {
 		result.Skipped = true
-		result.SkipReason = fmt.Sprintf(".claude directory does not exist: %s", claudeDir)
+		result.SkipReason = fmt.Sprintf("%s directory does not exist: %s", SyncedDirName(project), claudeDir)
+		return result
+	}
+
+	mappings, err := projectMappings(project)
+	if err != nil {
+		result.Skipped = true
+		result.SkipReason = err.Error()
 		return result
 	}
 
+	// Template data is built on first use since it needs the project's git state
+	var templateData *TemplateData
+
 	// Sync each resolved file
 	for _, file := range resolved {
-		dstPath := filepath.Join(claudeDir, file.RelPath)
+		relPath := toLocal(file.RelPath, mappings)
+		dstPath := destPath(project, claudeDir, relPath)
+		name := DisplayPath(relPath)
 
 		// Check if destination file already exists
 		fileExists := utils.FileExists(dstPath)
 
+		// Paths shadowed by the project's overlay are left alone
+		if _, ok := overlayFile(claudeDir, opts.Overlay, relPath); ok {
+			result.Overlaid++
+			if verbose {
+				fmt.Printf("  ↷ Overridden locally: %s\n", name)
+			}
+			continue
+		}
+
+		// Render templates before writing so the source is read as it was resolved
+		var rendered []byte
+		isTemplate := IsTemplate(relPath)
+		outputExists := isTemplate && utils.FileExists(TemplateOutput(dstPath))
+		if isTemplate {
+			if templateData == nil {
+				data := newTemplateData(project, claudeDir, opts.Vars)
+				templateData = &data
+			}
+			content, err := renderTemplateFile(file, dstPath, *templateData)
+			if err != nil {
+				result.Failed++
+				result.Errors = append(result.Errors, fmt.Errorf("%s: %w", name, err))
+				if verbose {
+					fmt.Fprintf(os.Stderr, "  ✗ Failed to render %s: %v\n", name, err)
+				}
+				continue
+			}
+			rendered = content
+		}
+
 		if dryRun {
 			if verbose {
 				if fileExists {
-					fmt.Printf("  [DRY RUN] Would overwrite: \033[31m%s\033[0m\n", file.RelPath)
+					fmt.Printf("  [DRY RUN] Would overwrite: \033[31m%s\033[0m\n", name)
 				} else {
-					fmt.Printf("  [DRY RUN] Would create: \033[32m%s\033[0m\n", file.RelPath)
+					fmt.Printf("  [DRY RUN] Would create: \033[32m%s\033[0m\n", name)
+				}
+				if isTemplate {
+					fmt.Printf("  [DRY RUN] Would render: %s\n", TemplateOutput(name))
 				}
 			}
 
@@ -146,28 +282,64 @@ func syncToProject(resolved []ResolvedFile, project config.ProjectPath, dryRun b
 			} else {
 				result.NewFiles++
 			}
+			if isTemplate {
+				if _, ok := overlayFile(claudeDir, opts.Overlay, TemplateOutput(relPath)); ok {
+					result.Overlaid++
+				} else if outputExists {
+					result.Overwritten++
+				} else {
+					result.NewFiles++
+				}
+			}
 			continue
 		}
 
-		// Actual file copy
-		if err := utils.CopyFile(file.AbsPath, dstPath); err != nil {
+		// Actual file copy (or write of generated content)
+		if err := writeResolvedFile(file, dstPath); err != nil {
 			result.Failed++
-			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", file.RelPath, err))
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", name, err))
 			if verbose {
-				fmt.Fprintf(os.Stderr, "  ✗ Failed to sync %s: %v\n", file.RelPath, err)
+				fmt.Fprintf(os.Stderr, "  ✗ Failed to sync %s: %v\n", name, err)
 			}
 			continue
 		}
 
+		if isTemplate {
+			outputRelPath := TemplateOutput(relPath)
+			if _, ok := overlayFile(claudeDir, opts.Overlay, outputRelPath); ok {
+				// The overlay shadows the rendered output as well
+				result.Overlaid++
+				if verbose {
+					fmt.Printf("  ↷ Overridden locally: %s\n", DisplayPath(outputRelPath))
+				}
+			} else if err := writeRenderedTemplate(file, TemplateOutput(dstPath), rendered); err != nil {
+				result.Failed++
+				result.Errors = append(result.Errors, fmt.Errorf("%s: %w", DisplayPath(outputRelPath), err))
+				if verbose {
+					fmt.Fprintf(os.Stderr, "  ✗ Failed to render %s: %v\n", name, err)
+				}
+				continue
+			} else {
+				if outputExists {
+					result.Overwritten++
+				} else {
+					result.NewFiles++
+				}
+				if verbose {
+					fmt.Printf("  ✓ Rendered: %s\n", DisplayPath(outputRelPath))
+				}
+			}
+		}
+
 		if fileExists {
 			result.Overwritten++
 			if verbose {
-				fmt.Printf("  ✓ Overwritten: \033[31m%s\033[0m\n", file.RelPath)
+				fmt.Printf("  ✓ Overwritten: \033[31m%s\033[0m\n", name)
 			}
 		} else {
 			result.NewFiles++
 			if verbose {
-				fmt.Printf("  ✓ Created: \033[32m%s\033[0m\n", file.RelPath)
+				fmt.Printf("  ✓ Created: \033[32m%s\033[0m\n", name)
 			}
 		}
 	}
@@ -175,10 +347,75 @@ func syncToProject(resolved []ResolvedFile, project config.ProjectPath, dryRun b
 	return result
 }
 
+// renderResolvedFile returns the content to write for a resolved file at dstPath.
+// generated is false when the source file should be copied as is.
+func renderResolvedFile(file ResolvedFile, dstPath string) (content []byte, generated bool, err error) {
+	switch {
+	case file.Sections != nil:
+		// Splice the shared sections into the destination's own content
+		existing, err := os.ReadFile(dstPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, false, fmt.Errorf("failed to read destination: %w", err)
+		}
+		content, err := ApplySharedSections(existing, file.Sections)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid shared section markers in destination: %w", err)
+		}
+		return content, true, nil
+	case file.Content != nil:
+		return file.Content, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// writeResolvedFile writes a resolved file to dstPath, using generated content when present
+func writeResolvedFile(file ResolvedFile, dstPath string) error {
+	content, generated, err := renderResolvedFile(file, dstPath)
+	if err != nil {
+		return err
+	}
+	if !generated {
+		return utils.CopyFile(file.AbsPath, dstPath)
+	}
+
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(file.AbsPath); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	return utils.WriteFile(dstPath, content, perm)
+}
+
+// writeRenderedTemplate writes rendered template output, using the template's permissions
+func writeRenderedTemplate(file ResolvedFile, dstPath string, content []byte) error {
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(file.AbsPath); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	return utils.WriteFile(dstPath, content, perm)
+}
+
+// resolvedHash returns the SHA256 hash of the content a resolved file will write to dstPath
+func resolvedHash(file ResolvedFile, dstPath string) (string, error) {
+	content, generated, err := renderResolvedFile(file, dstPath)
+	if err != nil {
+		return "", err
+	}
+	if !generated {
+		return utils.FileHash(file.AbsPath)
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // GetSyncSummary returns a formatted summary of sync results
 func GetSyncSummary(results []SyncResult) string {
 	totalNew := 0
 	totalOverwritten := 0
+	totalOverlaid := 0
 	totalFailed := 0
 	successfulProjects := 0
 	skippedProjects := 0
@@ -191,6 +428,7 @@ func GetSyncSummary(results []SyncResult) string {
 
 		totalNew += result.NewFiles
 		totalOverwritten += result.Overwritten
+		totalOverlaid += result.Overlaid
 		totalFailed += result.Failed
 
 		if result.Failed == 0 {
@@ -209,6 +447,9 @@ func GetSyncSummary(results []SyncResult) string {
 	summary += "\n"
 
 	summary += fmt.Sprintf("  Files: %d new, %d overwritten", totalNew, totalOverwritten)
+	if totalOverlaid > 0 {
+		summary += fmt.Sprintf(", %d overridden locally", totalOverlaid)
+	}
 	if totalFailed > 0 {
 		summary += fmt.Sprintf(", %d failed", totalFailed)
 	}
@@ -255,6 +496,12 @@ func PrintSyncResults(results []SyncResult, verbose bool) {
 				}
 				status += fmt.Sprintf("%d overwritten", result.Overwritten)
 			}
+			if result.Overlaid > 0 {
+				if status != "" {
+					status += ", "
+				}
+				status += fmt.Sprintf("%d overridden locally", result.Overlaid)
+			}
 			if status == "" {
 				status = "no changes"
 			}
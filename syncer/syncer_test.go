@@ -411,32 +411,3 @@ func TestSyncFiles_SkippedProject(t *testing.T) {
 		t.Error("Skip reason should be provided")
 	}
 }
-
-// TestExpandPath tests the expandPath helper function
-func TestExpandPath(t *testing.T) {
-	// Save original HOME
-	originalHome := os.Getenv("HOME")
-	defer os.Setenv("HOME", originalHome)
-
-	// Set a test HOME
-	testHome := "/home/testuser"
-	os.Setenv("HOME", testHome)
-
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		{"~/test", filepath.Join(testHome, "test")},
-		{"~/.claude", filepath.Join(testHome, ".claude")},
-		{"/absolute/path", "/absolute/path"},
-		{"relative/path", "relative/path"},
-		{"~", testHome},
-	}
-
-	for _, tt := range tests {
-		result := expandPath(tt.input)
-		if result != tt.expected {
-			t.Errorf("expandPath(%s) = %s, expected %s", tt.input, result, tt.expected)
-		}
-	}
-}
@@ -0,0 +1,97 @@
+package syncer
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/yugo-ibuki/dot-claude-sync/config"
+	"github.com/yugo-ibuki/dot-claude-sync/utils"
+)
+
+// TemplateSuffix marks a template source file. The source is synced as is and rendered
+// per destination into the same path without the suffix (e.g., CLAUDE.md.tmpl → CLAUDE.md).
+const TemplateSuffix = ".tmpl"
+
+// TemplateData is the data available to templates
+type TemplateData struct {
+	Project TemplateProject   // Destination project
+	Git     TemplateGit       // Git state of the destination project
+	Vars    map[string]string // Group-defined variables
+}
+
+// TemplateProject describes the destination project
+type TemplateProject struct {
+	Alias string // Project alias
+	Root  string // Project root (the directory containing .claude)
+	Path  string // Path to the .claude directory
+}
+
+// TemplateGit describes the git state of the destination project
+type TemplateGit struct {
+	Branch string // Current branch; empty if the project is not a git repository
+}
+
+// IsTemplate reports whether relPath is a template source
+func IsTemplate(relPath string) bool {
+	return strings.HasSuffix(relPath, TemplateSuffix) && relPath != TemplateSuffix && !strings.HasSuffix(relPath, "/"+TemplateSuffix)
+}
+
+// TemplateOutput returns the path a template source is rendered to
+func TemplateOutput(relPath string) string {
+	return strings.TrimSuffix(relPath, TemplateSuffix)
+}
+
+// newTemplateData builds the template data for a destination project
+func newTemplateData(project config.ProjectPath, claudeDir string, vars map[string]string) TemplateData {
+	root := projectRoot(project, claudeDir)
+	if vars == nil {
+		vars = map[string]string{}
+	}
+
+	return TemplateData{
+		Project: TemplateProject{
+			Alias: project.Alias,
+			Root:  root,
+			Path:  claudeDir,
+		},
+		Git: TemplateGit{
+			Branch: utils.GitBranch(root),
+		},
+		Vars: vars,
+	}
+}
+
+// RenderTemplate renders template content with the given data.
+// Referencing an undefined variable is an error.
+func RenderTemplate(name string, content []byte, data TemplateData) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// renderTemplateFile renders a resolved template source for the destination at dstPath
+func renderTemplateFile(file ResolvedFile, dstPath string, data TemplateData) ([]byte, error) {
+	source, generated, err := renderResolvedFile(file, dstPath)
+	if err != nil {
+		return nil, err
+	}
+	if !generated {
+		source, err = os.ReadFile(file.AbsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+	}
+
+	return RenderTemplate(file.RelPath, source, data)
+}
@@ -0,0 +1,213 @@
+package syncer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yugo-ibuki/dot-claude-sync/config"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	data := TemplateData{
+		Project: TemplateProject{Alias: "feature-a", Root: "/work/feature-a", Path: "/work/feature-a/.claude"},
+		Git:     TemplateGit{Branch: "feature/a"},
+		Vars:    map[string]string{"team": "platform"},
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "project and git fields",
+			content: "# {{ .Project.Alias }} ({{ .Git.Branch }}) at {{ .Project.Root }}",
+			want:    "# feature-a (feature/a) at /work/feature-a",
+		},
+		{
+			name:    "group variable",
+			content: "Owned by {{ .Vars.team }}",
+			want:    "Owned by platform",
+		},
+		{
+			name:    "undefined variable",
+			content: "{{ .Vars.missing }}",
+			wantErr: true,
+		},
+		{
+			name:    "invalid syntax",
+			content: "{{ .Project.Alias ",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderTemplate("CLAUDE.md.tmpl", []byte(tt.content), data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RenderTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, string(got))
+			}
+		})
+	}
+}
+
+func TestIsTemplate(t *testing.T) {
+	tests := []struct {
+		relPath string
+		want    bool
+	}{
+		{"CLAUDE.md.tmpl", true},
+		{"commands/review.md.tmpl", true},
+		{"CLAUDE.md", false},
+		{".tmpl", false},
+		{"commands/.tmpl", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsTemplate(tt.relPath); got != tt.want {
+			t.Errorf("IsTemplate(%q) = %v, want %v", tt.relPath, got, tt.want)
+		}
+	}
+}
+
+func TestSyncFiles_RendersTemplates(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	project1 := filepath.Join(tmpDir, "main", ".claude")
+	project2 := filepath.Join(tmpDir, "feature", ".claude")
+	for _, dir := range []string{project1, project2} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	source := "Worktree {{ .Project.Alias }} for {{ .Vars.team }}\n"
+	if err := os.WriteFile(filepath.Join(project1, "CLAUDE.md.tmpl"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Previously rendered output in another project must not compete with the template
+	stale := filepath.Join(project2, "CLAUDE.md")
+	if err := os.WriteFile(stale, []byte("Worktree feature for platform\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(stale, future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	projects := []config.ProjectPath{
+		{Alias: "main", Path: project1, Priority: 1},
+		{Alias: "feature", Path: project2, Priority: 2},
+	}
+
+	files, err := CollectFiles(projects, nil)
+	if err != nil {
+		t.Fatalf("CollectFiles failed: %v", err)
+	}
+
+	resolved, conflicts, err := ResolveConflicts(files, nil)
+	if err != nil {
+		t.Fatalf("ResolveConflicts failed: %v", err)
+	}
+	if len(resolved) != 1 || resolved[0].RelPath != "CLAUDE.md.tmpl" || len(conflicts) != 0 {
+		t.Fatalf("Expected only the template source to be resolved, got %+v", resolved)
+	}
+
+	results, err := SyncFilesWithOptions(resolved, projects, SyncOptions{
+		Force: true,
+		Vars:  map[string]string{"team": "platform"},
+	})
+	if err != nil {
+		t.Fatalf("SyncFilesWithOptions failed: %v", err)
+	}
+	if HasErrors(results) {
+		t.Fatalf("Unexpected sync errors: %+v", results)
+	}
+
+	for _, project := range projects {
+		tmpl, err := os.ReadFile(filepath.Join(project.Path, "CLAUDE.md.tmpl"))
+		if err != nil || string(tmpl) != source {
+			t.Errorf("%s: expected template source to be synced, got %q (%v)", project.Alias, tmpl, err)
+		}
+
+		rendered, err := os.ReadFile(filepath.Join(project.Path, "CLAUDE.md"))
+		if err != nil {
+			t.Fatalf("%s: rendered file missing: %v", project.Alias, err)
+		}
+		want := "Worktree " + project.Alias + " for platform\n"
+		if string(rendered) != want {
+			t.Errorf("%s: expected %q, got %q", project.Alias, want, string(rendered))
+		}
+	}
+
+	// A template error fails only that file
+	if err := os.WriteFile(filepath.Join(project1, "CLAUDE.md.tmpl"), []byte("{{ .Vars.unknown }}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	results, err = SyncFilesWithOptions(resolved, projects, SyncOptions{Force: true})
+	if err != nil {
+		t.Fatalf("SyncFilesWithOptions failed: %v", err)
+	}
+	if !HasErrors(results) || !strings.Contains(results[0].Errors[0].Error(), "CLAUDE.md.tmpl") {
+		t.Errorf("Expected render error, got %+v", results)
+	}
+}
+
+func TestSyncFiles_TemplateOutputOverwrites(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	project1 := filepath.Join(tmpDir, "main", ".claude")
+	project2 := filepath.Join(tmpDir, "feature", ".claude")
+	for _, dir := range []string{project1, project2} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	templatePath := filepath.Join(project1, "CLAUDE.md.tmpl")
+	if err := os.WriteFile(templatePath, []byte("Worktree {{ .Project.Alias }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A hand-written file where feature's rendered output goes
+	if err := os.WriteFile(filepath.Join(project2, "CLAUDE.md"), []byte("hand-written\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resolved := []ResolvedFile{{RelPath: "CLAUDE.md.tmpl", AbsPath: templatePath, Source: "main", Priority: 1}}
+	projects := []config.ProjectPath{
+		{Alias: "main", Path: project1, Priority: 1},
+		{Alias: "feature", Path: project2, Priority: 2},
+	}
+
+	overwrites, err := findOverwrites(resolved, projects, SyncOptions{})
+	if err != nil {
+		t.Fatalf("findOverwrites failed: %v", err)
+	}
+	if len(overwrites) != 1 || overwrites[0].DestProject != "feature" || overwrites[0].RelPath != "CLAUDE.md" {
+		t.Errorf("Expected the rendered output in feature to be reported, got %+v", overwrites)
+	}
+
+	// Dry-run counts the rendered output along with the template source
+	results, err := SyncFilesWithOptions(resolved, projects, SyncOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("SyncFilesWithOptions failed: %v", err)
+	}
+	if results[1].NewFiles != 1 || results[1].Overwritten != 1 {
+		t.Errorf("Expected feature to count 1 new and 1 overwritten file, got %+v", results[1])
+	}
+
+	// Output that already matches the rendered content is not reported
+	if err := os.WriteFile(filepath.Join(project2, "CLAUDE.md"), []byte("Worktree feature\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if overwrites, err = findOverwrites(resolved, projects, SyncOptions{}); err != nil || len(overwrites) != 0 {
+		t.Errorf("Expected no overwrites, got %+v (%v)", overwrites, err)
+	}
+}
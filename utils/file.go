@@ -2,20 +2,24 @@ package utils
 
 import (
 	"bufio"
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"os/user"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
 // CopyFile copies a file from src to dst
 func CopyFile(src, dst string) error {
 	// Expand home directory
-	src = expandPath(src)
-	dst = expandPath(dst)
+	src = ExpandHome(src)
+	dst = ExpandHome(dst)
 
 	// Check if source and destination are the same file
 	srcAbs, err := filepath.Abs(src)
@@ -67,10 +71,30 @@ func CopyFile(src, dst string) error {
 	return nil
 }
 
+// WriteFile writes data to dst, creating parent directories as needed.
+// If dst already exists its permissions are preserved, otherwise perm is used.
+func WriteFile(dst string, data []byte, perm os.FileMode) error {
+	dst = ExpandHome(dst)
+
+	if err := EnsureDir(filepath.Dir(dst)); err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(dst); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	if err := os.WriteFile(dst, data, perm); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
 // CopyDir recursively copies a directory from src to dst
 func CopyDir(src, dst string) error {
-	src = expandPath(src)
-	dst = expandPath(dst)
+	src = ExpandHome(src)
+	dst = ExpandHome(dst)
 
 	srcInfo, err := os.Stat(src)
 	if err != nil {
@@ -111,8 +135,8 @@ func CopyDir(src, dst string) error {
 
 // CopyDirExclude recursively copies a directory from src to dst, excluding specified directories
 func CopyDirExclude(src, dst string, excludeDirs []string) error {
-	src = expandPath(src)
-	dst = expandPath(dst)
+	src = ExpandHome(src)
+	dst = ExpandHome(dst)
 
 	srcInfo, err := os.Stat(src)
 	if err != nil {
@@ -164,7 +188,7 @@ func CopyDirExclude(src, dst string, excludeDirs []string) error {
 
 // RemoveFile removes a file or directory (recursively if directory)
 func RemoveFile(path string) error {
-	path = expandPath(path)
+	path = ExpandHome(path)
 
 	if !FileExists(path) {
 		return nil // Already doesn't exist
@@ -180,7 +204,7 @@ func RemoveFile(path string) error {
 
 // RemoveDir recursively removes a directory
 func RemoveDir(path string) error {
-	path = expandPath(path)
+	path = ExpandHome(path)
 
 	if !FileExists(path) {
 		return nil // Already doesn't exist
@@ -195,8 +219,8 @@ func RemoveDir(path string) error {
 
 // MoveFile moves or renames a file from src to dst
 func MoveFile(src, dst string) error {
-	src = expandPath(src)
-	dst = expandPath(dst)
+	src = ExpandHome(src)
+	dst = ExpandHome(dst)
 
 	// Ensure destination directory exists
 	dstDir := filepath.Dir(dst)
@@ -213,7 +237,7 @@ func MoveFile(src, dst string) error {
 
 // EnsureDir creates a directory and all parent directories if they don't exist
 func EnsureDir(path string) error {
-	path = expandPath(path)
+	path = ExpandHome(path)
 
 	if err := os.MkdirAll(path, 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
@@ -224,14 +248,14 @@ func EnsureDir(path string) error {
 
 // FileExists checks if a file or directory exists
 func FileExists(path string) bool {
-	path = expandPath(path)
+	path = ExpandHome(path)
 	_, err := os.Stat(path)
 	return err == nil
 }
 
 // FileHash calculates SHA256 hash of a file
 func FileHash(path string) (string, error) {
-	path = expandPath(path)
+	path = ExpandHome(path)
 
 	file, err := os.Open(path)
 	if err != nil {
@@ -249,7 +273,7 @@ func FileHash(path string) (string, error) {
 
 // IsDirectory checks if the given path is a directory
 func IsDirectory(path string) bool {
-	path = expandPath(path)
+	path = ExpandHome(path)
 	info, err := os.Stat(path)
 	if err != nil {
 		return false
@@ -271,22 +295,142 @@ func FormatSize(size int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
 }
 
-// expandPath expands ~ to home directory
-func expandPath(path string) string {
-	if len(path) == 0 || path[0] != '~' {
-		return path
+// ParseSize parses a human-readable size such as "512", "100KB", "1.5MB" or "2GiB" into bytes.
+// Units are binary (1KB = 1024 bytes), matching FormatSize.
+func ParseSize(s string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	if value == "" {
+		return 0, fmt.Errorf("size cannot be empty")
+	}
+
+	multipliers := []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"KIB", 1 << 10}, {"MIB", 1 << 20}, {"GIB", 1 << 30},
+		{"KB", 1 << 10}, {"MB", 1 << 20}, {"GB", 1 << 30},
+		{"K", 1 << 10}, {"M", 1 << 20}, {"G", 1 << 30},
+		{"B", 1},
+	}
+
+	multiplier := 1.0
+	for _, m := range multipliers {
+		if strings.HasSuffix(value, m.suffix) {
+			multiplier = m.multiplier
+			value = strings.TrimSpace(strings.TrimSuffix(value, m.suffix))
+			break
+		}
 	}
 
-	homeDir, err := os.UserHomeDir()
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(number) || number < 0 {
+		return 0, fmt.Errorf("invalid size '%s' (e.g., 500KB, 10MB, 1GB)", s)
+	}
+
+	// Also rejects infinity; the conversion of larger values is undefined
+	size := number * multiplier
+	if size >= math.MaxInt64 {
+		return 0, fmt.Errorf("size '%s' is too large", s)
+	}
+
+	return int64(size), nil
+}
+
+// IsBinaryFile reports whether a file looks binary, i.e. its first 8000 bytes contain a NUL byte
+func IsBinaryFile(path string) (bool, error) {
+	path = ExpandHome(path)
+
+	file, err := os.Open(path)
 	if err != nil {
+		return false, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	buf := make([]byte, 8000)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return bytes.IndexByte(buf[:n], 0) >= 0, nil
+}
+
+// ExpandPath expands a leading ~ or ~user to the home directory and environment variables
+// written as $VAR, ${VAR} or ${VAR:-default} (default is used when VAR is unset or empty).
+// Unset variables without a default expand to an empty string.
+func ExpandPath(path string) string {
+	path = ExpandHome(path)
+	if !strings.Contains(path, "$") {
+		return path
+	}
+
+	expanded := os.Expand(path, lookupVar)
+	if expanded == "" {
+		return expanded
+	}
+	return filepath.Clean(expanded) // An empty variable may leave a double separator
+}
+
+// UnsetVars returns the environment variables referenced in path that are not set
+// and have no default
+func UnsetVars(path string) []string {
+	var unset []string
+	os.Expand(path, func(name string) string {
+		if strings.Contains(name, ":-") {
+			return ""
+		}
+		if _, ok := os.LookupEnv(name); !ok {
+			unset = append(unset, name)
+		}
+		return ""
+	})
+	return unset
+}
+
+// lookupVar returns the value of an environment variable reference, which may
+// carry a default as in ${VAR:-default}
+func lookupVar(name string) string {
+	if i := strings.Index(name, ":-"); i >= 0 {
+		if value := os.Getenv(name[:i]); value != "" {
+			return value
+		}
+		return name[i+2:]
+	}
+	return os.Getenv(name)
+}
+
+// ExpandHome expands a leading ~ (current user) or ~user to the home directory.
+// File operations use it rather than ExpandPath, since a $ in a file name is not a variable.
+func ExpandHome(path string) string {
+	if len(path) == 0 || path[0] != '~' {
 		return path
 	}
 
-	if len(path) == 1 {
+	name, rest := path[1:], ""
+	if i := strings.IndexAny(name, "/"+string(filepath.Separator)); i >= 0 {
+		name, rest = name[:i], name[i:]
+	}
+
+	var homeDir string
+	if name == "" {
+		dir, err := os.UserHomeDir()
+		if err != nil {
+			return path
+		}
+		homeDir = dir
+	} else {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return path
+		}
+		homeDir = u.HomeDir
+	}
+
+	if rest == "" {
 		return homeDir
 	}
 
-	return filepath.Join(homeDir, path[1:])
+	return filepath.Join(homeDir, rest)
 }
 
 // Confirm prompts the user for yes/no confirmation
@@ -303,10 +447,10 @@ func Confirm(message string) bool {
 	return response == "y" || response == "yes"
 }
 
-// ValidateAndNormalizePath validates and normalizes a path relative to .claude directory.
-// It expects the path to start with ".claude/" and removes that prefix.
-// Returns the normalized path without the ".claude/" prefix and an error if validation fails.
-func ValidateAndNormalizePath(path string) (string, error) {
+// ValidateAndNormalizePath validates and normalizes a path relative to a synced directory
+// such as .claude. It expects the path to start with dir (e.g., ".claude/") and removes that prefix.
+// Returns the normalized path without the prefix and an error if validation fails.
+func ValidateAndNormalizePath(path, dir string) (string, error) {
 	// Trim whitespace
 	path = strings.TrimSpace(path)
 
@@ -319,23 +463,23 @@ func ValidateAndNormalizePath(path string) (string, error) {
 		return "", fmt.Errorf("path cannot contain '..' (parent directory references)")
 	}
 
-	// Check if path starts with .claude or .claude/
-	if !strings.HasPrefix(path, ".claude") {
-		return "", fmt.Errorf("path must start with '.claude/' (e.g., '.claude/commands/foo.md')")
+	// Check if path starts with the synced directory
+	if !strings.HasPrefix(path, dir) {
+		return "", fmt.Errorf("path must start with '%s/' (e.g., '%s/commands/foo.md')", dir, dir)
 	}
 
-	// Remove .claude prefix
-	// Handle both ".claude" and ".claude/"
-	if path == ".claude" {
-		return "", fmt.Errorf("path cannot be just '.claude', must specify a file or directory inside .claude")
+	// Remove the directory prefix
+	// Handle both "<dir>" and "<dir>/"
+	if path == dir {
+		return "", fmt.Errorf("path cannot be just '%s', must specify a file or directory inside %s", dir, dir)
 	}
 
-	// Remove ".claude/" prefix
-	normalized := strings.TrimPrefix(path, ".claude/")
-	normalized = strings.TrimPrefix(normalized, ".claude\\") // Handle Windows path separator
+	// Remove "<dir>/" prefix
+	normalized := strings.TrimPrefix(path, dir+"/")
+	normalized = strings.TrimPrefix(normalized, dir+"\\") // Handle Windows path separator
 
 	if normalized == "" || normalized == path {
-		return "", fmt.Errorf("path must specify a file or directory inside .claude (e.g., '.claude/commands/foo.md')")
+		return "", fmt.Errorf("path must specify a file or directory inside %s (e.g., '%s/commands/foo.md')", dir, dir)
 	}
 
 	// Normalize path separators
@@ -352,7 +496,7 @@ func ValidateAndNormalizePath(path string) (string, error) {
 // DeleteEmptyFolders recursively deletes all empty directories in the given path
 // Returns a list of deleted folder paths and any error encountered
 func DeleteEmptyFolders(rootPath string) ([]string, error) {
-	rootPath = expandPath(rootPath)
+	rootPath = ExpandHome(rootPath)
 
 	if !FileExists(rootPath) {
 		return []string{}, fmt.Errorf("path does not exist: %s", rootPath)
@@ -415,7 +559,7 @@ func deleteEmptyFoldersRecursive(dirPath string, deletedFolders *[]string) error
 // only empty files (0 bytes) and/or empty subdirectories
 // Returns a list of directory paths that should be deleted
 func FindDirectoriesWithOnlyEmptyFiles(rootPath string) ([]string, error) {
-	rootPath = expandPath(rootPath)
+	rootPath = ExpandHome(rootPath)
 
 	if !FileExists(rootPath) {
 		return []string{}, fmt.Errorf("path does not exist: %s", rootPath)
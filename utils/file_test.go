@@ -2,6 +2,7 @@ package utils
 
 import (
 	"os"
+	"os/user"
 	"path/filepath"
 	"testing"
 )
@@ -148,6 +149,22 @@ func TestCopyFile(t *testing.T) {
 		}
 	})
 
+	// A $ in a file name is not expanded as a variable
+	t.Run("dollar in file name", func(t *testing.T) {
+		t.Setenv("ARGUMENTS", "other")
+		dstFile := filepath.Join(tmpDir, "commands", "$ARGUMENTS.md")
+
+		if err := CopyFile(srcFile, dstFile); err != nil {
+			t.Fatalf("CopyFile failed: %v", err)
+		}
+		if _, err := os.Stat(dstFile); err != nil {
+			t.Errorf("Expected %s to be written: %v", dstFile, err)
+		}
+		if FileExists(filepath.Join(tmpDir, "commands", "other.md")) {
+			t.Error("File name should not be expanded")
+		}
+	})
+
 	// Test copy non-existent source
 	t.Run("non-existent source", func(t *testing.T) {
 		err := CopyFile(filepath.Join(tmpDir, "nonexistent.txt"), filepath.Join(tmpDir, "dest.txt"))
@@ -481,11 +498,101 @@ func TestFormatSize(t *testing.T) {
 	}
 }
 
+// TestParseSize tests the ParseSize function
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    int64
+		expectError bool
+	}{
+		{input: "512", expected: 512},
+		{input: "512B", expected: 512},
+		{input: "100KB", expected: 100 * 1024},
+		{input: "1.5mb", expected: 1572864},
+		{input: "2 GiB", expected: 2 << 30},
+		{input: "10M", expected: 10 << 20},
+		{input: "", expectError: true},
+		{input: "ten MB", expectError: true},
+		{input: "-1KB", expectError: true},
+		{input: "-0.5", expectError: true},
+		{input: "NaN", expectError: true},
+		{input: "nanMB", expectError: true},
+		{input: "Inf", expectError: true},
+		{input: "+InfGB", expectError: true},
+		{input: "-Inf", expectError: true},
+		{input: "1e30GB", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseSize(tt.input)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ParseSize(%q) error = %v, expectError %v", tt.input, err, tt.expectError)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseSize(%q) = %d, expected %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestExpandPath tests home directory and environment variable expansion
+func TestExpandPath(t *testing.T) {
+	testHome := "/home/testuser"
+	t.Setenv("HOME", testHome)
+	t.Setenv("WORKSPACE", "/srv/work")
+	t.Setenv("EMPTY", "")
+	os.Unsetenv("DCS_UNSET")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"~/test", filepath.Join(testHome, "test")},
+		{"~/.claude", filepath.Join(testHome, ".claude")},
+		{"/absolute/path", "/absolute/path"},
+		{"relative/path", "relative/path"},
+		{"~", testHome},
+		{"$HOME/projects", testHome + "/projects"},
+		{"${WORKSPACE}/app", "/srv/work/app"},
+		{"${DCS_UNSET:-/opt/work}/app", "/opt/work/app"},
+		{"${EMPTY:-/opt/work}/app", "/opt/work/app"},
+		{"${WORKSPACE:-/opt/work}/app", "/srv/work/app"},
+		{"~/$DCS_UNSET/app", filepath.Join(testHome, "app")},
+		{"~no-such-user-dcs/app", "~no-such-user-dcs/app"},
+	}
+
+	if current, err := user.Current(); err == nil {
+		tests = append(tests, struct {
+			input    string
+			expected string
+		}{"~" + current.Username + "/app", filepath.Join(current.HomeDir, "app")})
+	}
+
+	for _, tt := range tests {
+		if result := ExpandPath(tt.input); result != tt.expected {
+			t.Errorf("ExpandPath(%s) = %s, expected %s", tt.input, result, tt.expected)
+		}
+	}
+}
+
+// TestUnsetVars tests detection of unset environment variables in paths
+func TestUnsetVars(t *testing.T) {
+	t.Setenv("WORKSPACE", "/srv/work")
+	os.Unsetenv("DCS_UNSET")
+
+	unset := UnsetVars("$WORKSPACE/${DCS_UNSET}/${DCS_UNSET:-x}")
+	if len(unset) != 1 || unset[0] != "DCS_UNSET" {
+		t.Errorf("Expected [DCS_UNSET], got %v", unset)
+	}
+}
+
 // TestValidateAndNormalizePath tests the ValidateAndNormalizePath function
 func TestValidateAndNormalizePath(t *testing.T) {
 	tests := []struct {
 		name        string
 		input       string
+		dir         string // Synced directory; empty means .claude
 		expected    string
 		expectError bool
 		errorMsg    string
@@ -550,11 +657,29 @@ func TestValidateAndNormalizePath(t *testing.T) {
 			expected:    "commands",
 			expectError: false,
 		},
+		{
+			name:        "group with another synced directory",
+			input:       ".cursor/rules/foo.mdc",
+			dir:         ".cursor",
+			expected:    "rules/foo.mdc",
+			expectError: false,
+		},
+		{
+			name:        ".claude path in a group with another synced directory",
+			input:       ".claude/commands/foo.md",
+			dir:         ".cursor",
+			expectError: true,
+			errorMsg:    "path must start with '.cursor/'",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := ValidateAndNormalizePath(tt.input)
+			dir := tt.dir
+			if dir == "" {
+				dir = ".claude"
+			}
+			result, err := ValidateAndNormalizePath(tt.input, dir)
 
 			if tt.expectError {
 				if err == nil {
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitBranch returns the current branch of the repository at dir, or "" if unavailable
+func GitBranch(dir string) string {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	branch := strings.TrimSpace(string(output))
+	if branch == "HEAD" {
+		// Detached HEAD
+		return ""
+	}
+	return branch
+}
+
+// GitCommonDir returns the git directory shared by all worktrees of the repository at dir,
+// or "" if dir is not in a git repository
+func GitCommonDir(dir string) string {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "--git-common-dir")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	commonDir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(dir, commonDir)
+	}
+	if resolved, err := filepath.EvalSymlinks(commonDir); err == nil {
+		return resolved
+	}
+	return filepath.Clean(commonDir)
+}